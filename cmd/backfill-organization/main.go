@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"report-service/internal/report/repository"
+	"report-service/pkg/config"
+	"report-service/pkg/db"
+)
+
+// backfill-organization gắn organization_id cho report, report history và status change ghi trước khi có field này,
+// organization suy ra theo học sinh từ các history / report đã có organization_id.
+// Usage: backfill-organization configs/config.yaml
+func main() {
+	filePath := "configs/config.yaml"
+	if len(os.Args) > 1 && os.Args[1] != "" {
+		filePath = os.Args[1]
+	}
+
+	config.LoadConfig(filePath)
+
+	db.ConnectMongoDB()
+
+	ctx := context.Background()
+	historyRepo := repository.NewReportHistoryRepository(db.ReportHistoryCollection, repository.HistoryStorage{
+		Mode:               config.AppConfig.History.Mode,
+		CheckpointInterval: config.AppConfig.History.CheckpointInterval,
+	})
+
+	reportRepo := repository.NewReportRepository(db.ReportCollection, db.CounterCollection)
+
+	organizations, err := historyRepo.OrganizationsByStudent(ctx)
+	if err != nil {
+		log.Fatalf("Get student organizations failed: %v", err)
+	}
+	// history là nguồn chính, report chỉ bổ sung học sinh chưa có history mang organization
+	fromReports, err := reportRepo.OrganizationsByStudent(ctx)
+	if err != nil {
+		log.Fatalf("Get student organizations failed: %v", err)
+	}
+	for studentID, organizationID := range fromReports {
+		if _, ok := organizations[studentID]; !ok {
+			organizations[studentID] = organizationID
+		}
+	}
+
+	reports, err := reportRepo.BackfillOrganization(ctx, organizations)
+	if err != nil {
+		log.Fatalf("Backfill report organization failed after %d reports: %v", reports, err)
+	}
+
+	statusChangeRepo := repository.NewReportStatusChangeRepository(db.ReportStatusChangeCollection)
+	statusChanges, err := statusChangeRepo.BackfillOrganization(ctx, organizations)
+	if err != nil {
+		log.Fatalf("Backfill status change organization failed after %d entries: %v", statusChanges, err)
+	}
+
+	histories, err := historyRepo.BackfillOrganization(ctx, organizations)
+	if err != nil {
+		log.Fatalf("Backfill report history organization failed after %d entries: %v", histories, err)
+	}
+
+	log.Printf("Backfilled organization for %d reports, %d report history entries and %d status changes (%d students)", reports, histories, statusChanges, len(organizations))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"report-service/internal/report/repository"
+	"report-service/pkg/config"
+	"report-service/pkg/constants"
+	"report-service/pkg/db"
+)
+
+// compact-history chuyển các report history full snapshot cũ sang dạng delta.
+// Usage: compact-history configs/config.yaml
+func main() {
+	filePath := "configs/config.yaml"
+	if len(os.Args) > 1 && os.Args[1] != "" {
+		filePath = os.Args[1]
+	}
+
+	config.LoadConfig(filePath)
+
+	db.ConnectMongoDB()
+
+	historyRepo := repository.NewReportHistoryRepository(db.ReportHistoryCollection, repository.HistoryStorage{
+		Mode:               constants.HistoryStorageDelta,
+		CheckpointInterval: config.AppConfig.History.CheckpointInterval,
+	})
+
+	compacted, err := historyRepo.CompactAll(context.Background())
+	if err != nil {
+		log.Fatalf("Compact report histories failed after %d entries: %v", compacted, err)
+	}
+
+	log.Printf("Compacted %d report history entries", compacted)
+}
@@ -48,7 +48,7 @@ func main() {
 	//db
 	db.ConnectMongoDB()
 
-	r := router.SetupRouter(consulClient, db.ReportCollection, db.ReportHistoryCollection, db.ReportPlanTemplateCollection, db.ReportTranslateCollection)
+	r := router.SetupRouter(consulClient, db.ReportCollection, db.ReportHistoryCollection, db.ReportPlanTemplateCollection, db.ReportTranslateCollection, db.IdempotencyKeyCollection, db.ReportSchemaCollection, db.ReportSectionCollection, db.ReportStatusModelCollection, db.ReportDeadlineCollection, db.ReportStatusChangeCollection, db.ReportExportJobCollection, db.CounterCollection, db.ReportReminderCollection)
 	port := cfg.Server.Port
 	if err := r.Run(":" + port); err != nil {
 		log.Fatal("Failed to run server:", err)
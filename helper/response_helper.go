@@ -11,6 +11,7 @@ const (
 	ErrInvalidRequest   = "ERR_INVALID_REQUEST"
 	ErrNotFound         = "ERR_NOT_FOUND"
 	ErrInternal         = "ERR_INTERNAL"
+	ErrConflict         = "ERR_CONFLICT"
 )
 
 type APIResponse struct {
@@ -30,6 +31,11 @@ func SendSuccess(c *gin.Context, statusCode int, message string, data interface{
 }
 
 func SendError(c *gin.Context, statusCode int, err error, errorCode string) {
+	SendErrorWithData(c, statusCode, err, errorCode, nil)
+}
+
+// SendErrorWithData trả lỗi kèm data để client xử lý tiếp (vd: version hiện tại khi bị conflict)
+func SendErrorWithData(c *gin.Context, statusCode int, err error, errorCode string, data interface{}) {
 	var errMsg string
 	if err != nil {
 		errMsg = err.Error()
@@ -47,6 +53,7 @@ func SendError(c *gin.Context, statusCode int, err error, errorCode string) {
 
 	c.JSON(statusCode, APIResponse{
 		StatusCode: statusCode,
+		Data:       data,
 		Error:      errMsg,
 		Message:    errMsg,
 		ErrorCode:  errorCode,
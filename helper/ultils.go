@@ -52,28 +52,30 @@ func SafeString(s *string) string {
 	return *s
 }
 
-func GetLatestTimeStr(updatedAt, managerUpdatedAt string) string {
-	if updatedAt == "" && managerUpdatedAt == "" {
-		return ""
-	}
+// sectionTimeLayouts: các format updated_at / manager_updated_at client gửi lên
+var sectionTimeLayouts = []string{
+	"2006-01-02T15:04:05.000Z07:00",
+	"2006-01-02T15:04:05.000000",
+	"2006-01-02T15:04:05.000",
+}
 
-	layouts := []string{
-		"2006-01-02T15:04:05.000Z07:00",
-		"2006-01-02T15:04:05.000000",
-		"2006-01-02T15:04:05.000",
+// ParseTimeStr parse thời gian trong section, trả về zero time nếu không đúng format
+func ParseTimeStr(s string) time.Time {
+	for _, layout := range sectionTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
 	}
+	return time.Time{}
+}
 
-	parseTime := func(s string) time.Time {
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, s); err == nil {
-				return t
-			}
-		}
-		return time.Time{}
+func GetLatestTimeStr(updatedAt, managerUpdatedAt string) string {
+	if updatedAt == "" && managerUpdatedAt == "" {
+		return ""
 	}
 
-	t1 := parseTime(updatedAt)
-	t2 := parseTime(managerUpdatedAt)
+	t1 := ParseTimeStr(updatedAt)
+	t2 := ParseTimeStr(managerUpdatedAt)
 
 	if t1.IsZero() && t2.IsZero() {
 		return ""
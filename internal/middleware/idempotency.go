@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"report-service/helper"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/logger"
+	"report-service/pkg/constants"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder giữ lại body response để lưu cho các lần replay
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency: request ghi có header Idempotency-Key chỉ được thực thi một lần cho mỗi user,
+// các lần gửi lại cùng key + cùng payload nhận lại đúng response cũ. Phải đặt sau Secured.
+func Idempotency(repo repository.IdempotencyRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			helper.SendError(c, http.StatusBadRequest, errors.New("idempotency key is too long"), helper.ErrInvalidRequest)
+			c.Abort()
+			return
+		}
+
+		userID := helper.GetUserID(c.Request.Context())
+		if currentUser, ok := c.Request.Context().Value(constants.CurrentUserKey).(*gw_response.CurrentUser); ok && userID == "" {
+			userID = currentUser.ID
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+		hash.Write(body)
+
+		record := &model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			RequestHash: hex.EncodeToString(hash.Sum(nil)),
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+		}
+
+		existing, err := repo.Reserve(c.Request.Context(), record)
+		if err != nil {
+			helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+			c.Abort()
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.RequestHash != record.RequestHash:
+				helper.SendError(c, http.StatusUnprocessableEntity, errors.New("idempotency key was already used with a different request"), helper.ErrInvalidRequest)
+			case existing.Status != constants.IdempotencyStatusCompleted:
+				helper.SendError(c, http.StatusConflict, errors.New("a request with this idempotency key is still in progress"), helper.ErrConflict)
+			default:
+				c.Header(constants.IdempotencyReplayedHeader, strconv.FormatBool(true))
+				c.Data(existing.ResponseCode, existing.ContentType, existing.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		// lỗi phía server thì bỏ key để client retry được, còn lại lưu response để replay
+		ctx := c.Request.Context()
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			err = repo.Release(ctx, userID, key)
+		} else {
+			err = repo.Complete(ctx, userID, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+		}
+		if err != nil {
+			logger.WriteLogEx("error", err.Error(), map[string]interface{}{
+				"idempotency_key": key,
+				"path":            c.Request.URL.Path,
+			})
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"math"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"sort"
+	"time"
+)
+
+// Stats: thống kê một tập khoảng thời gian
+type Stats struct {
+	Count   int
+	Average time.Duration
+	Median  time.Duration
+	P90     time.Duration
+}
+
+// ReviewCycle: một lần section đi từ lúc teacher nộp đến lúc manager accept
+type ReviewCycle struct {
+	Acceptance  *model.ReportStatusChange
+	SubmittedAt time.Time
+	AcceptedAt  time.Time
+}
+
+func (c ReviewCycle) Duration() time.Duration {
+	return c.AcceptedAt.Sub(c.SubmittedAt)
+}
+
+type sectionKey struct {
+	reportID string
+	section  string
+}
+
+// ReviewCycles ghép lần nộp đầu tiên của teacher với lần accept kế tiếp của từng section.
+// Bị trả về rồi nộp lại vẫn tính từ lần nộp đầu, để đo cả thời gian sửa lại.
+// changes phải theo thứ tự thời gian.
+func ReviewCycles(changes []*model.ReportStatusChange) []ReviewCycle {
+	submittedAt := make(map[sectionKey]time.Time)
+	var cycles []ReviewCycle
+
+	for _, c := range changes {
+		key := sectionKey{reportID: c.ReportID.Hex(), section: c.Section}
+
+		switch {
+		case c.To == string(constants.SectionStatusAccepted):
+			if start, ok := submittedAt[key]; ok {
+				cycles = append(cycles, ReviewCycle{Acceptance: c, SubmittedAt: start, AcceptedAt: c.ChangedAt})
+				delete(submittedAt, key)
+			}
+		case c.To == string(constants.SectionStatusEmpty):
+			// restore về trống thì bỏ lần nộp đang chờ
+			delete(submittedAt, key)
+		case isSubmission(c):
+			if _, ok := submittedAt[key]; !ok {
+				submittedAt[key] = c.ChangedAt
+			}
+		}
+	}
+
+	return cycles
+}
+
+// TimeInStatus tính thời gian section nằm ở mỗi status, chỉ tính các lần đã rời status đó.
+// changes phải theo thứ tự thời gian.
+func TimeInStatus(changes []*model.ReportStatusChange) map[string][]time.Duration {
+	last := make(map[sectionKey]*model.ReportStatusChange)
+	res := make(map[string][]time.Duration)
+
+	for _, c := range changes {
+		key := sectionKey{reportID: c.ReportID.Hex(), section: c.Section}
+		if prev, ok := last[key]; ok && prev.To == c.From {
+			res[prev.To] = append(res[prev.To], c.ChangedAt.Sub(prev.ChangedAt))
+		}
+		last[key] = c
+	}
+
+	return res
+}
+
+// Summarize tính trung bình, trung vị và p90 (nearest-rank)
+func Summarize(durations []time.Duration) Stats {
+	if len(durations) == 0 {
+		return Stats{}
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+
+	n := len(sorted)
+	median := sorted[n/2]
+	if n%2 == 0 {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+
+	return Stats{
+		Count:   n,
+		Average: total / time.Duration(n),
+		Median:  median,
+		P90:     sorted[int(math.Ceil(0.9*float64(n)))-1],
+	}
+}
+
+func isSubmission(c *model.ReportStatusChange) bool {
+	if c.Role != string(constants.ReportHistoryRoleTeacher) {
+		return false
+	}
+	return c.To == string(constants.SectionStatusManager) || c.To == string(constants.SectionStatusDone)
+}
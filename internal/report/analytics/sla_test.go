@@ -0,0 +1,183 @@
+package analytics
+
+import (
+	"reflect"
+	"report-service/internal/report/model"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func minutes(values ...int) []time.Duration {
+	res := make([]time.Duration, len(values))
+	for i, v := range values {
+		res[i] = time.Duration(v) * time.Minute
+	}
+	return res
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      Stats
+	}{
+		{"empty", nil, Stats{}},
+		{"single", minutes(5), Stats{Count: 1, Average: 5 * time.Minute, Median: 5 * time.Minute, P90: 5 * time.Minute}},
+		{"odd count unsorted", minutes(30, 10, 20), Stats{Count: 3, Average: 20 * time.Minute, Median: 20 * time.Minute, P90: 30 * time.Minute}},
+		{"even count averages middle", minutes(40, 10, 30, 20), Stats{Count: 4, Average: 25 * time.Minute, Median: 25 * time.Minute, P90: 40 * time.Minute}},
+		{"ten values p90 is 9th", minutes(1, 2, 3, 4, 5, 6, 7, 8, 9, 100), Stats{Count: 10, Average: 145 * time.Minute / 10, Median: 5*time.Minute + 30*time.Second, P90: 9 * time.Minute}},
+		{"eleven values p90 is 10th", minutes(11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1), Stats{Count: 11, Average: 6 * time.Minute, Median: 6 * time.Minute, P90: 10 * time.Minute}},
+		{"outlier skews average only", minutes(1, 1, 1, 1, 96), Stats{Count: 5, Average: 20 * time.Minute, Median: time.Minute, P90: 96 * time.Minute}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := append([]time.Duration(nil), tt.durations...)
+			if got := Summarize(tt.durations); got != tt.want {
+				t.Errorf("Summarize = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(input, tt.durations) {
+				t.Errorf("input reordered: %v", tt.durations)
+			}
+		})
+	}
+}
+
+var (
+	slaStart  = time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+	reportOne = primitive.NewObjectID()
+	reportTwo = primitive.NewObjectID()
+)
+
+func change(reportID primitive.ObjectID, section, from, to, role string, after time.Duration) *model.ReportStatusChange {
+	return &model.ReportStatusChange{
+		ReportID:  reportID,
+		Section:   section,
+		From:      from,
+		To:        to,
+		Role:      role,
+		ChangedAt: slaStart.Add(after),
+	}
+}
+
+func TestReviewCycles(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []*model.ReportStatusChange
+		want    []time.Duration
+	}{
+		{
+			name: "submit then accept",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "teacher", "manager", "teacher", 0),
+				change(reportOne, "goal", "manager", "done", "manager", time.Hour),
+				change(reportOne, "goal", "done", "accepted", "manager", 3*time.Hour),
+			},
+			want: []time.Duration{3 * time.Hour},
+		},
+		{
+			name: "sent back counts from first submission",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "teacher", "manager", "teacher", 0),
+				change(reportOne, "goal", "manager", "teacher", "manager", time.Hour),
+				change(reportOne, "goal", "teacher", "manager", "teacher", 2*time.Hour),
+				change(reportOne, "goal", "manager", "accepted", "manager", 5*time.Hour),
+			},
+			want: []time.Duration{5 * time.Hour},
+		},
+		{
+			name: "restore to empty drops pending submission",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "teacher", "manager", "teacher", 0),
+				change(reportOne, "goal", "manager", "empty", "manager", time.Hour),
+				change(reportOne, "goal", "teacher", "manager", "teacher", 4*time.Hour),
+				change(reportOne, "goal", "manager", "accepted", "manager", 6*time.Hour),
+			},
+			want: []time.Duration{2 * time.Hour},
+		},
+		{
+			name: "manager moves are not submissions",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "accepted", "done", "manager", 0),
+				change(reportOne, "goal", "done", "accepted", "manager", time.Hour),
+			},
+		},
+		{
+			name: "new cycle after acceptance",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "teacher", "done", "teacher", 0),
+				change(reportOne, "goal", "done", "accepted", "manager", time.Hour),
+				change(reportOne, "goal", "accepted", "teacher", "manager", 2*time.Hour),
+				change(reportOne, "goal", "teacher", "manager", "teacher", 3*time.Hour),
+				change(reportOne, "goal", "manager", "accepted", "manager", 7*time.Hour),
+			},
+			want: []time.Duration{time.Hour, 4 * time.Hour},
+		},
+		{
+			name: "sections and reports are tracked separately",
+			changes: []*model.ReportStatusChange{
+				change(reportOne, "goal", "teacher", "manager", "teacher", 0),
+				change(reportTwo, "goal", "teacher", "manager", "teacher", time.Hour),
+				change(reportOne, "note", "teacher", "manager", "teacher", 2*time.Hour),
+				change(reportTwo, "goal", "manager", "accepted", "manager", 3*time.Hour),
+				change(reportOne, "goal", "manager", "accepted", "manager", 4*time.Hour),
+			},
+			want: []time.Duration{2 * time.Hour, 4 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles := ReviewCycles(tt.changes)
+			var got []time.Duration
+			for _, c := range cycles {
+				got = append(got, c.Duration())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("durations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewCyclePercentiles(t *testing.T) {
+	var changes []*model.ReportStatusChange
+	for i := 1; i <= 10; i++ {
+		section := "section-" + string(rune('a'+i))
+		changes = append(changes,
+			change(reportOne, section, "teacher", "manager", "teacher", 0),
+			change(reportOne, section, "manager", "accepted", "manager", time.Duration(i)*time.Hour),
+		)
+	}
+
+	var durations []time.Duration
+	for _, c := range ReviewCycles(changes) {
+		durations = append(durations, c.Duration())
+	}
+
+	want := Stats{Count: 10, Average: 5*time.Hour + 30*time.Minute, Median: 5*time.Hour + 30*time.Minute, P90: 9 * time.Hour}
+	if got := Summarize(durations); got != want {
+		t.Errorf("Summarize = %+v, want %+v", got, want)
+	}
+}
+
+func TestTimeInStatus(t *testing.T) {
+	changes := []*model.ReportStatusChange{
+		change(reportOne, "goal", "empty", "teacher", "teacher", 0),
+		change(reportOne, "goal", "teacher", "manager", "teacher", time.Hour),
+		change(reportOne, "goal", "manager", "teacher", "manager", 3*time.Hour),
+		change(reportOne, "goal", "teacher", "manager", "teacher", 4*time.Hour),
+		change(reportTwo, "goal", "empty", "teacher", "teacher", 4*time.Hour),
+		change(reportOne, "goal", "manager", "accepted", "manager", 6*time.Hour),
+	}
+
+	want := map[string][]time.Duration{
+		"teacher": {time.Hour, time.Hour},
+		"manager": {2 * time.Hour, 2 * time.Hour},
+	}
+	if got := TimeInStatus(changes); !reflect.DeepEqual(got, want) {
+		t.Errorf("TimeInStatus = %v, want %v", got, want)
+	}
+}
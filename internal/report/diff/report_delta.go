@@ -0,0 +1,168 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"report-service/helper"
+	"report-service/internal/report/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ReportDelta tính phần thay đổi để từ oldReport ra newReport (oldReport nil = report rỗng)
+func ReportDelta(oldReport, newReport *model.Report) (*model.ReportDelta, error) {
+	oldDoc, err := toDoc(oldReport)
+	if err != nil {
+		return nil, err
+	}
+	newDoc, err := toDoc(newReport)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &model.ReportDelta{}
+
+	for _, key := range unionKeys(oldDoc, newDoc) {
+		if key == "report_data" {
+			continue
+		}
+		oldValue, inOld := oldDoc[key]
+		newValue, inNew := newDoc[key]
+		if !inNew {
+			delta.Unset = append(delta.Unset, key)
+			continue
+		}
+		if inOld && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if delta.Set == nil {
+			delta.Set = bson.M{}
+		}
+		delta.Set[key] = newValue
+	}
+
+	oldData := helper.ToBsonM(oldDoc["report_data"])
+	newData := helper.ToBsonM(newDoc["report_data"])
+
+	for _, section := range unionKeys(oldData, newData) {
+		oldSection, inOld := oldData[section]
+		newSection, inNew := newData[section]
+
+		if !inNew {
+			delta.Changes = append(delta.Changes, model.FieldDelta{Section: section, Unset: true})
+			continue
+		}
+
+		oldFields, oldIsDoc := asDoc(oldSection)
+		newFields, newIsDoc := asDoc(newSection)
+		if !inOld || !oldIsDoc || !newIsDoc {
+			if !inOld || !reflect.DeepEqual(oldSection, newSection) {
+				delta.Changes = append(delta.Changes, model.FieldDelta{Section: section, Value: newSection})
+			}
+			continue
+		}
+
+		for _, field := range unionKeys(oldFields, newFields) {
+			oldValue, inOldField := oldFields[field]
+			newValue, inNewField := newFields[field]
+			if !inNewField {
+				delta.Changes = append(delta.Changes, model.FieldDelta{Section: section, Field: field, Unset: true})
+				continue
+			}
+			if inOldField && reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			delta.Changes = append(delta.Changes, model.FieldDelta{Section: section, Field: field, Value: newValue})
+		}
+	}
+
+	return delta, nil
+}
+
+// ApplyDelta dựng lại version mới từ base + delta, base không bị thay đổi
+func ApplyDelta(base *model.Report, delta *model.ReportDelta) (*model.Report, error) {
+	doc, err := toDoc(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if delta != nil {
+		for key, value := range delta.Set {
+			doc[key] = value
+		}
+		for _, key := range delta.Unset {
+			delete(doc, key)
+		}
+
+		data := helper.ToBsonM(doc["report_data"])
+		for _, change := range delta.Changes {
+			if change.Field == "" {
+				if change.Unset {
+					delete(data, change.Section)
+				} else if section, ok := asDoc(change.Value); ok {
+					data[change.Section] = section
+				} else {
+					data[change.Section] = change.Value
+				}
+				continue
+			}
+
+			section, ok := asDoc(data[change.Section])
+			if !ok {
+				section = bson.M{}
+			}
+			if change.Unset {
+				delete(section, change.Field)
+			} else {
+				section[change.Field] = change.Value
+			}
+			data[change.Section] = section
+		}
+		doc["report_data"] = data
+	}
+
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal report failed: %w", err)
+	}
+
+	var report model.Report
+	if err := bson.Unmarshal(raw, &report); err != nil {
+		return nil, fmt.Errorf("unmarshal report failed: %w", err)
+	}
+	return &report, nil
+}
+
+// toDoc chuyển report sang bson.M (bản copy) để so sánh theo đúng dữ liệu lưu trong DB
+func toDoc(report *model.Report) (bson.M, error) {
+	doc := bson.M{}
+	if report == nil {
+		return doc, nil
+	}
+
+	raw, err := bson.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("marshal report failed: %w", err)
+	}
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal report failed: %w", err)
+	}
+	return doc, nil
+}
+
+// asDoc nhận cả bson.M lẫn bson.D (giá trị interface{} decode từ Mongo là bson.D)
+func asDoc(v interface{}) (bson.M, bool) {
+	switch d := v.(type) {
+	case bson.M:
+		return d, true
+	case map[string]interface{}:
+		return bson.M(d), true
+	case bson.D:
+		m := make(bson.M, len(d))
+		for _, e := range d {
+			m[e.Key] = e.Value
+		}
+		return m, true
+	}
+	return nil, false
+}
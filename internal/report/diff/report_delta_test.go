@@ -0,0 +1,214 @@
+package diff
+
+import (
+	"reflect"
+	"report-service/internal/report/model"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var deltaReportID = primitive.NewObjectID()
+
+func deltaReport(status string, data bson.M) *model.Report {
+	return &model.Report{
+		ID:         deltaReportID,
+		StudentID:  "student-1",
+		TopicID:    "topic-1",
+		TermID:     "term-1",
+		Language:   "vi",
+		Status:     status,
+		ReportData: data,
+		Version:    1,
+		CreatedAt:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestReportDeltaRoundTrip(t *testing.T) {
+	base := deltaReport("teacher", bson.M{
+		"goal": bson.M{
+			"content": "đọc sách",
+			"status":  "teacher",
+			"extra":   bson.M{"tags": bson.A{"a", "b"}, "meta": bson.M{"level": int32(1), "by": "t1"}},
+		},
+		"note":  bson.M{"content": "ghi chú", "color": "red"},
+		"title": "Báo cáo",
+	})
+
+	tests := []struct {
+		name string
+		old  *model.Report
+		new  func() *model.Report
+	}{
+		{
+			name: "no change",
+			old:  base,
+			new:  func() *model.Report { return deltaReport("teacher", cloneData(t, base.ReportData)) },
+		},
+		{
+			name: "from empty report",
+			old:  nil,
+			new:  func() *model.Report { return deltaReport("teacher", cloneData(t, base.ReportData)) },
+		},
+		{
+			name: "top-level fields set and removed",
+			old: func() *model.Report {
+				r := deltaReport("teacher", cloneData(t, base.ReportData))
+				r.EditorID = "teacher-1"
+				r.OrganizationID = "org-1"
+				return r
+			}(),
+			new: func() *model.Report {
+				r := deltaReport("manager", cloneData(t, base.ReportData))
+				r.OrganizationID = "org-2"
+				r.Version = 2
+				return r
+			},
+		},
+		{
+			name: "nested map inside field",
+			old:  base,
+			new: func() *model.Report {
+				data := cloneData(t, base.ReportData)
+				data["goal"] = bson.M{
+					"content": "đọc sách",
+					"status":  "teacher",
+					"extra":   bson.M{"tags": bson.A{"a"}, "meta": bson.M{"level": int32(2)}},
+				}
+				return deltaReport("teacher", data)
+			},
+		},
+		{
+			name: "fields added and removed",
+			old:  base,
+			new: func() *model.Report {
+				data := cloneData(t, base.ReportData)
+				data["note"] = bson.M{"content": "ghi chú mới", "manager_comment": "ok"}
+				return deltaReport("teacher", data)
+			},
+		},
+		{
+			name: "sections added and removed",
+			old:  base,
+			new: func() *model.Report {
+				data := cloneData(t, base.ReportData)
+				delete(data, "note")
+				delete(data, "title")
+				data["now"] = bson.M{"content": "hiện tại", "extra": bson.M{"score": 9.5}}
+				return deltaReport("teacher", data)
+			},
+		},
+		{
+			name: "section changes type",
+			old:  base,
+			new: func() *model.Report {
+				data := cloneData(t, base.ReportData)
+				data["title"] = bson.M{"content": "Báo cáo"}
+				data["note"] = "chỉ còn chuỗi"
+				return deltaReport("teacher", data)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.new()
+			delta, err := ReportDelta(tt.old, want)
+			if err != nil {
+				t.Fatalf("ReportDelta: %v", err)
+			}
+
+			// áp trực tiếp và áp sau khi lưu/đọc lại từ Mongo (giá trị về dạng bson.D) phải ra cùng kết quả
+			deltas := map[string]*model.ReportDelta{"direct": delta, "stored": storeDelta(t, delta)}
+			for kind, d := range deltas {
+				got, err := ApplyDelta(tt.old, d)
+				if err != nil {
+					t.Fatalf("%s ApplyDelta: %v", kind, err)
+				}
+				assertSameReport(t, kind, got, want)
+			}
+		})
+	}
+}
+
+func TestReportDeltaOnlyChanges(t *testing.T) {
+	old := deltaReport("teacher", bson.M{
+		"goal": bson.M{"content": "a", "status": "teacher"},
+		"note": bson.M{"content": "b"},
+	})
+	updated := deltaReport("teacher", bson.M{
+		"goal": bson.M{"content": "a2", "status": "teacher"},
+		"note": bson.M{"content": "b"},
+	})
+
+	delta, err := ReportDelta(old, updated)
+	if err != nil {
+		t.Fatalf("ReportDelta: %v", err)
+	}
+	if len(delta.Set) != 0 || len(delta.Unset) != 0 {
+		t.Errorf("set = %v, unset = %v, want no top-level change", delta.Set, delta.Unset)
+	}
+	want := []model.FieldDelta{{Section: "goal", Field: "content", Value: "a2"}}
+	if !reflect.DeepEqual(delta.Changes, want) {
+		t.Errorf("changes = %+v, want %+v", delta.Changes, want)
+	}
+}
+
+func TestApplyDeltaKeepsBase(t *testing.T) {
+	base := deltaReport("teacher", bson.M{"goal": bson.M{"content": "a"}})
+	delta := &model.ReportDelta{Changes: []model.FieldDelta{
+		{Section: "goal", Field: "content", Value: "b"},
+		{Section: "note", Unset: true},
+	}}
+
+	if _, err := ApplyDelta(base, delta); err != nil {
+		t.Fatalf("ApplyDelta: %v", err)
+	}
+	if got := base.ReportData["goal"].(bson.M)["content"]; got != "a" {
+		t.Errorf("base goal.content = %v, want a", got)
+	}
+}
+
+func cloneData(t *testing.T, data bson.M) bson.M {
+	t.Helper()
+	raw, err := bson.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal data: %v", err)
+	}
+	var res bson.M
+	if err := bson.Unmarshal(raw, &res); err != nil {
+		t.Fatalf("unmarshal data: %v", err)
+	}
+	return res
+}
+
+func storeDelta(t *testing.T, delta *model.ReportDelta) *model.ReportDelta {
+	t.Helper()
+	raw, err := bson.Marshal(delta)
+	if err != nil {
+		t.Fatalf("marshal delta: %v", err)
+	}
+	var stored model.ReportDelta
+	if err := bson.Unmarshal(raw, &stored); err != nil {
+		t.Fatalf("unmarshal delta: %v", err)
+	}
+	return &stored
+}
+
+func assertSameReport(t *testing.T, kind string, got, want *model.Report) {
+	t.Helper()
+	gotDoc, err := toDoc(got)
+	if err != nil {
+		t.Fatalf("%s: %v", kind, err)
+	}
+	wantDoc, err := toDoc(want)
+	if err != nil {
+		t.Fatalf("%s: %v", kind, err)
+	}
+	if !reflect.DeepEqual(gotDoc, wantDoc) {
+		t.Errorf("%s:\n got  %v\n want %v", kind, gotDoc, wantDoc)
+	}
+}
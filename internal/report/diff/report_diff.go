@@ -0,0 +1,82 @@
+package diff
+
+import (
+	"reflect"
+	"report-service/helper"
+	"report-service/internal/report/model"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// FieldChange là một thay đổi ở report_data.<section>.<field>.
+// Section rỗng nghĩa là field ở cấp report (vd: status).
+type FieldChange struct {
+	Section  string      `bson:"section,omitempty" json:"section,omitempty"`
+	Field    string      `bson:"field" json:"field"`
+	OldValue interface{} `bson:"old_value" json:"old_value"`
+	NewValue interface{} `bson:"new_value" json:"new_value"`
+}
+
+// Reports so sánh 2 snapshot report, nil được coi như report rỗng
+func Reports(oldReport, newReport *model.Report) []FieldChange {
+	var oldStatus, newStatus string
+	var oldData, newData bson.M
+	if oldReport != nil {
+		oldStatus = oldReport.Status
+		oldData = oldReport.ReportData
+	}
+	if newReport != nil {
+		newStatus = newReport.Status
+		newData = newReport.ReportData
+	}
+
+	var changes []FieldChange
+	if oldStatus != newStatus {
+		changes = append(changes, FieldChange{Field: "status", OldValue: oldStatus, NewValue: newStatus})
+	}
+
+	return append(changes, ReportData(oldData, newData)...)
+}
+
+// ReportData so sánh report_data theo từng section/field, kết quả sắp xếp ổn định
+func ReportData(oldData, newData bson.M) []FieldChange {
+	var changes []FieldChange
+
+	for _, section := range unionKeys(oldData, newData) {
+		oldSection := helper.ToBsonM(oldData[section])
+		newSection := helper.ToBsonM(newData[section])
+
+		for _, field := range unionKeys(oldSection, newSection) {
+			oldValue, newValue := oldSection[field], newSection[field]
+			if reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			changes = append(changes, FieldChange{
+				Section:  section,
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+
+	return changes
+}
+
+func unionKeys(a, b bson.M) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		seen[k] = struct{}{}
+	}
+	for k := range b {
+		seen[k] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"reflect"
+	"report-service/internal/report/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// MergeConflict là field bị sửa ở cả 2 phía (current và incoming) với giá trị khác nhau.
+// Section rỗng nghĩa là field ở cấp report (vd: status).
+type MergeConflict struct {
+	Section       string      `json:"section,omitempty"`
+	Field         string      `json:"field"`
+	BaseValue     interface{} `json:"base_value"`
+	CurrentValue  interface{} `json:"current_value"`
+	IncomingValue interface{} `json:"incoming_value"`
+}
+
+// MergeReport gộp 3-way status + report_data của incoming vào current dựa trên base (version client đã đọc).
+// incoming là payload upload (partial theo section/field), kết quả trả về cũng là payload partial:
+// chỉ giữ các field incoming thực sự thay đổi so với base để không ghi đè thay đổi của phía kia.
+func MergeReport(base, current, incoming *model.Report) (string, bson.M, []MergeConflict) {
+	var conflicts []MergeConflict
+
+	status := incoming.Status
+	switch {
+	case equalValue(incoming.Status, base.Status):
+		status = current.Status
+	case equalValue(current.Status, base.Status), equalValue(current.Status, incoming.Status):
+	default:
+		conflicts = append(conflicts, MergeConflict{
+			Field:         "status",
+			BaseValue:     base.Status,
+			CurrentValue:  current.Status,
+			IncomingValue: incoming.Status,
+		})
+	}
+
+	data, dataConflicts := MergeReportData(base.ReportData, current.ReportData, incoming.ReportData)
+	return status, data, append(conflicts, dataConflicts...)
+}
+
+// MergeReportData gộp report_data theo từng section/field:
+//   - incoming không đổi so với base → bỏ qua (giữ giá trị current)
+//   - chỉ incoming đổi, hoặc 2 phía đổi giống nhau → lấy incoming
+//   - cả 2 phía đổi khác nhau → conflict
+func MergeReportData(base, current, incoming bson.M) (bson.M, []MergeConflict) {
+	merged := bson.M{}
+	var conflicts []MergeConflict
+
+	for _, section := range unionKeys(incoming, nil) {
+		incomingFields, ok := asDoc(incoming[section])
+		if !ok {
+			// section không phải object thì repository tự bỏ qua, giữ nguyên payload
+			merged[section] = incoming[section]
+			continue
+		}
+		baseFields, _ := asDoc(normalizeValue(base[section]))
+		currentFields, _ := asDoc(normalizeValue(current[section]))
+
+		// repository đọc section dạng map[string]interface{}
+		fields := map[string]interface{}{}
+		for _, field := range unionKeys(incomingFields, nil) {
+			incomingValue := incomingFields[field]
+			baseValue, currentValue := baseFields[field], currentFields[field]
+
+			switch {
+			case equalValue(incomingValue, baseValue):
+				continue
+			case equalValue(currentValue, baseValue), equalValue(currentValue, incomingValue):
+				fields[field] = incomingValue
+			default:
+				conflicts = append(conflicts, MergeConflict{
+					Section:       section,
+					Field:         field,
+					BaseValue:     baseValue,
+					CurrentValue:  currentValue,
+					IncomingValue: incomingValue,
+				})
+			}
+		}
+
+		if len(fields) > 0 {
+			merged[section] = fields
+		}
+	}
+
+	return merged, conflicts
+}
+
+// equalValue so sánh sau khi chuẩn hoá qua bson để payload JSON và dữ liệu Mongo cùng kiểu
+func equalValue(a, b interface{}) bool {
+	return reflect.DeepEqual(normalizeValue(a), normalizeValue(b))
+}
+
+func normalizeValue(v interface{}) interface{} {
+	raw, err := bson.Marshal(bson.M{"v": v})
+	if err != nil {
+		return v
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return v
+	}
+	if d, ok := asDoc(doc["v"]); ok {
+		return d
+	}
+	return doc["v"]
+}
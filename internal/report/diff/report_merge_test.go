@@ -0,0 +1,150 @@
+package diff
+
+import (
+	"reflect"
+	"report-service/internal/report/model"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMergeReportData(t *testing.T) {
+	base := bson.M{
+		"goal": bson.M{"content": "a", "status": "teacher"},
+		"note": bson.M{"content": "n"},
+	}
+
+	tests := []struct {
+		name      string
+		current   bson.M
+		incoming  bson.M
+		want      bson.M
+		conflicts []MergeConflict
+	}{
+		{
+			name:     "only incoming changed",
+			current:  base,
+			incoming: bson.M{"goal": bson.M{"content": "b", "status": "teacher"}},
+			want:     bson.M{"goal": map[string]interface{}{"content": "b"}},
+		},
+		{
+			name: "different fields changed on each side",
+			current: bson.M{
+				"goal": bson.M{"content": "a", "status": "manager"},
+				"note": bson.M{"content": "n"},
+			},
+			incoming: bson.M{"goal": bson.M{"content": "b", "status": "teacher"}},
+			want:     bson.M{"goal": map[string]interface{}{"content": "b"}},
+		},
+		{
+			name: "both sides made the same change",
+			current: bson.M{
+				"goal": bson.M{"content": "b", "status": "teacher"},
+			},
+			incoming: bson.M{"goal": bson.M{"content": "b"}},
+			want:     bson.M{"goal": map[string]interface{}{"content": "b"}},
+		},
+		{
+			name:     "incoming unchanged keeps current",
+			current:  bson.M{"goal": bson.M{"content": "c", "status": "teacher"}},
+			incoming: bson.M{"goal": bson.M{"content": "a"}, "note": bson.M{"content": "n"}},
+			want:     bson.M{},
+		},
+		{
+			name:     "new section and field",
+			current:  base,
+			incoming: bson.M{"now": bson.M{"content": "x"}, "note": bson.M{"color": "red"}},
+			want: bson.M{
+				"now":  map[string]interface{}{"content": "x"},
+				"note": map[string]interface{}{"color": "red"},
+			},
+		},
+		{
+			name:     "both sides changed the same field",
+			current:  bson.M{"goal": bson.M{"content": "c", "status": "teacher"}},
+			incoming: bson.M{"goal": bson.M{"content": "b", "status": "manager"}},
+			want:     bson.M{"goal": map[string]interface{}{"status": "manager"}},
+			conflicts: []MergeConflict{
+				{Section: "goal", Field: "content", BaseValue: "a", CurrentValue: "c", IncomingValue: "b"},
+			},
+		},
+		{
+			name:     "current removed field incoming changed",
+			current:  bson.M{"goal": bson.M{"status": "teacher"}},
+			incoming: bson.M{"goal": bson.M{"content": "b"}},
+			want:     bson.M{},
+			conflicts: []MergeConflict{
+				{Section: "goal", Field: "content", BaseValue: "a", CurrentValue: nil, IncomingValue: "b"},
+			},
+		},
+		{
+			name:     "conflicts in several sections",
+			current:  bson.M{"goal": bson.M{"content": "c"}, "note": bson.M{"content": "m"}},
+			incoming: bson.M{"goal": bson.M{"content": "b"}, "note": bson.M{"content": "o"}},
+			want:     bson.M{},
+			conflicts: []MergeConflict{
+				{Section: "goal", Field: "content", BaseValue: "a", CurrentValue: "c", IncomingValue: "b"},
+				{Section: "note", Field: "content", BaseValue: "n", CurrentValue: "m", IncomingValue: "o"},
+			},
+		},
+		{
+			name:     "non-object section passes through",
+			current:  base,
+			incoming: bson.M{"title": "Báo cáo"},
+			want:     bson.M{"title": "Báo cáo"},
+		},
+		{
+			name:     "stored bson.D compares with json payload",
+			current:  bson.M{"goal": bson.D{{Key: "content", Value: "a"}, {Key: "score", Value: int32(5)}}},
+			incoming: bson.M{"goal": map[string]interface{}{"content": "a", "score": int32(5)}},
+			want:     bson.M{"goal": map[string]interface{}{"score": int32(5)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts := MergeReportData(base, tt.current, tt.incoming)
+			if !reflect.DeepEqual(merged, tt.want) {
+				t.Errorf("merged = %#v, want %#v", merged, tt.want)
+			}
+			if !reflect.DeepEqual(conflicts, tt.conflicts) {
+				t.Errorf("conflicts = %+v, want %+v", conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func TestMergeReportStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		current   string
+		incoming  string
+		want      string
+		conflicts int
+	}{
+		{"incoming unchanged", "teacher", "manager", "teacher", "manager", 0},
+		{"only incoming changed", "teacher", "teacher", "manager", "manager", 0},
+		{"same change", "teacher", "manager", "manager", "manager", 0},
+		{"both changed", "teacher", "done", "manager", "manager", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, conflicts := MergeReport(
+				&model.Report{Status: tt.base},
+				&model.Report{Status: tt.current},
+				&model.Report{Status: tt.incoming},
+			)
+			if status != tt.want {
+				t.Errorf("status = %q, want %q", status, tt.want)
+			}
+			if len(conflicts) != tt.conflicts {
+				t.Fatalf("conflicts = %+v, want %d", conflicts, tt.conflicts)
+			}
+			if tt.conflicts > 0 && (conflicts[0].Field != "status" || conflicts[0].Section != "") {
+				t.Errorf("conflict = %+v, want report-level status", conflicts[0])
+			}
+		})
+	}
+}
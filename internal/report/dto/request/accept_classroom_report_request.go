@@ -0,0 +1,9 @@
+package request
+
+type AcceptClassroomReportRequest4Web struct {
+	ClassroomID   string   `json:"classroom_id" binding:"required"`
+	TermID        string   `json:"term_id" binding:"required"`
+	TopicID       string   `json:"topic_id" binding:"required"`
+	UniqueLangKey string   `json:"unique_lang_key" binding:"required"`
+	Sections      []string `json:"sections" binding:"required,min=1"`
+}
@@ -0,0 +1,15 @@
+package request
+
+type EditingLockRequest4App struct {
+	StudentID string `json:"student_id" binding:"required"`
+	TopicID   string `json:"topic_id" binding:"required"`
+	TermID    string `json:"term_id" binding:"required"`
+	Language  string `json:"language" binding:"required"`
+}
+
+type EditingLockRequest4Web struct {
+	StudentID     string `json:"student_id" binding:"required"`
+	TopicID       string `json:"topic_id" binding:"required"`
+	TermID        string `json:"term_id" binding:"required"`
+	UniqueLangKey string `json:"unique_lang_key" binding:"required"`
+}
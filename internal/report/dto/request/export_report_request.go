@@ -0,0 +1,18 @@
+package request
+
+type ExportReportRequest4Web struct {
+	StudentID     string `form:"student_id" binding:"required"`
+	TopicID       string `form:"topic_id" binding:"required"`
+	TermID        string `form:"term_id" binding:"required"`
+	UniqueLangKey string `form:"unique_lang_key" binding:"required"`
+	// IncludeComments: kèm nhận xét của manager dưới từng phần
+	IncludeComments bool `form:"include_comments"`
+}
+
+type CreateReportExportJobRequest4Web struct {
+	ClassroomID   string `json:"classroom_id" binding:"required"`
+	TermID        string `json:"term_id" binding:"required"`
+	TopicID       string `json:"topic_id"` // rỗng = mọi topic
+	UniqueLangKey string `json:"unique_lang_key" binding:"required"`
+	Format        string `json:"format" binding:"required,oneof=pdf html docx"`
+}
@@ -6,3 +6,10 @@ type GetClassroomReportRequest4Web struct {
 	UniqueLangKey string `json:"unique_lang_key" binding:"required"`
 	ClassroomID   string `json:"classroom_id" binding:"required"`
 }
+
+type ClassroomReportEventRequest4Web struct {
+	TopicID       string `form:"topic_id" binding:"required"`
+	TermID        string `form:"term_id" binding:"required"`
+	UniqueLangKey string `form:"unique_lang_key" binding:"required"`
+	ClassroomID   string `form:"classroom_id" binding:"required"`
+}
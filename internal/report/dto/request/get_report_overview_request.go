@@ -8,3 +8,14 @@ type GetReportOverViewByClassroomRequest struct {
 	TermID      string `json:"term_id" binding:"required"`
 	ClassroomID string `json:"classroom_id" binding:"required"`
 }
+
+type ExportReportOverViewAllClassroomRequest4Web struct {
+	TermID string `form:"term_id" binding:"required"`
+	Format string `form:"format" binding:"required,oneof=csv xlsx"`
+}
+
+type ExportReportOverViewByClassroomRequest4Web struct {
+	TermID      string `form:"term_id" binding:"required"`
+	ClassroomID string `form:"classroom_id" binding:"required"`
+	Format      string `form:"format" binding:"required,oneof=csv xlsx"`
+}
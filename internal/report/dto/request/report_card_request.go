@@ -0,0 +1,10 @@
+package request
+
+type GetReportCardRequest4Web struct {
+	StudentID      string `form:"student_id" binding:"required"`
+	TermID         string `form:"term_id" binding:"required"`
+	UniqueLangKey  string `form:"unique_lang_key" binding:"required"`
+	TargetLanguage string `form:"target_language"`
+	// IncludeComments: kèm nhận xét của manager dưới từng phần
+	IncludeComments bool `form:"include_comments"`
+}
@@ -0,0 +1,15 @@
+package request
+
+import "time"
+
+type GetReportDeadlinesRequest4Web struct {
+	TermID string `form:"term_id"`
+}
+
+// ReportDeadlineRequest4Web: topic_id / section rỗng = áp dụng cho mọi topic / section của term
+type ReportDeadlineRequest4Web struct {
+	TermID  string    `json:"term_id" binding:"required"`
+	TopicID string    `json:"topic_id"`
+	Section string    `json:"section"`
+	DueAt   time.Time `json:"due_at" binding:"required"`
+}
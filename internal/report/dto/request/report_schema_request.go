@@ -0,0 +1,8 @@
+package request
+
+import "report-service/internal/report/model"
+
+// UpdateReportSchemaRequest4Web: phần mở rộng schema của organization (section/field thêm hoặc ghi đè)
+type UpdateReportSchemaRequest4Web struct {
+	Sections map[string]model.SectionSchema `json:"sections" binding:"required"`
+}
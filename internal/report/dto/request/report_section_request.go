@@ -0,0 +1,23 @@
+package request
+
+import "report-service/internal/report/model"
+
+type CreateReportSectionRequest4Web struct {
+	Key           string                `json:"key" binding:"required"`
+	Title         string                `json:"title" binding:"required"`
+	Type          string                `json:"type" binding:"required,oneof=text rating checklist"`
+	Rating        *model.RatingScale    `json:"rating"`
+	Items         []model.ChecklistItem `json:"items"`
+	Order         int                   `json:"order"`
+	TrackProgress bool                  `json:"track_progress"`
+}
+
+// UpdateReportSectionRequest4Web: key không đổi được sau khi tạo
+type UpdateReportSectionRequest4Web struct {
+	Title         string                `json:"title" binding:"required"`
+	Type          string                `json:"type" binding:"required,oneof=text rating checklist"`
+	Rating        *model.RatingScale    `json:"rating"`
+	Items         []model.ChecklistItem `json:"items"`
+	Order         int                   `json:"order"`
+	TrackProgress bool                  `json:"track_progress"`
+}
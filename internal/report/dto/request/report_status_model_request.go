@@ -0,0 +1,7 @@
+package request
+
+import "report-service/internal/report/model"
+
+type UpdateReportStatusModelRequest4Web struct {
+	Statuses []model.StatusDefinition `json:"statuses" binding:"required,min=1,dive"`
+}
@@ -0,0 +1,5 @@
+package request
+
+type RestoreReportRequest4Web struct {
+	Version *int64 `json:"version" binding:"required"`
+}
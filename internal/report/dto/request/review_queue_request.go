@@ -0,0 +1,7 @@
+package request
+
+type GetReviewQueueRequest4Web struct {
+	TermID        string `form:"term_id" binding:"required"`
+	UniqueLangKey string `form:"unique_lang_key"`
+	Sort          string `form:"sort" binding:"omitempty,oneof=oldest newest"`
+}
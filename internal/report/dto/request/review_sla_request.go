@@ -0,0 +1,6 @@
+package request
+
+type GetReviewSLARequest4Web struct {
+	TermID        string `form:"term_id" binding:"required"`
+	UniqueLangKey string `form:"unique_lang_key"`
+}
@@ -0,0 +1,16 @@
+package request
+
+import "time"
+
+type SearchReportHistoryRequest4Web struct {
+	ReportID    string     `form:"report_id"`
+	StudentID   string     `form:"student_id"`
+	ClassroomID string     `form:"classroom_id"`
+	EditorID    string     `form:"editor_id"`
+	EditorRole  string     `form:"editor_role"`
+	Type        string     `form:"type"`
+	From        *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To          *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor      string     `form:"cursor"`
+	Limit       int64      `form:"limit" binding:"omitempty,min=1,max=100"`
+}
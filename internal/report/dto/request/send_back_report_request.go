@@ -0,0 +1,12 @@
+package request
+
+type SendBackReportRequest4Web struct {
+	StudentID     string   `json:"student_id" binding:"required"`
+	TopicID       string   `json:"topic_id" binding:"required"`
+	TermID        string   `json:"term_id" binding:"required"`
+	UniqueLangKey string   `json:"unique_lang_key" binding:"required"`
+	Sections      []string `json:"sections" binding:"required,min=1"`
+	Reason        string   `json:"reason" binding:"required"`
+	Version       *int64   `json:"version"`
+	ClassroomID   string   `json:"classroom_id"` // không bắt buộc, có thì history khỏi tra lớp qua classroom service
+}
@@ -0,0 +1,10 @@
+package request
+
+type SyncChangesRequest4App struct {
+	SyncToken string `form:"sync_token"`
+	Limit     int64  `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+type SyncPushRequest4App struct {
+	Items []UploadReport4AppRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
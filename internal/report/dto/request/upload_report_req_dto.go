@@ -7,6 +7,10 @@ type UploadReport4AppRequest struct {
 	Language   string                 `json:"language" binding:"required"`
 	Status     string                 `json:"status" binding:"required"`
 	ReportData map[string]interface{} `json:"report_data" binding:"required"`
+	Version    *int64                 `json:"version"`
+
+	// ClassroomID không bắt buộc, có thì history khỏi tra lớp qua classroom service
+	ClassroomID string `json:"classroom_id"`
 }
 
 type UploadReport4AWebRequest struct {
@@ -15,8 +19,9 @@ type UploadReport4AWebRequest struct {
 	TermID        string                 `json:"term_id" binding:"required"`
 	UniqueLangKey string                 `json:"unique_lang_key" binding:"required"`
 	Status        string                 `json:"status" binding:"required"`
-	Editing       bool                   `json:"editing"`
 	ReportData    map[string]interface{} `json:"report_data" binding:"required"`
+	Version       *int64                 `json:"version"`
+	ClassroomID   string                 `json:"classroom_id"` // không bắt buộc, có thì history khỏi tra lớp qua classroom service
 }
 
 type NoteRequest struct {
@@ -100,4 +105,5 @@ type UploadClassroomReport4WebRequest struct {
 	ClassroomID   string                 `json:"classroom_id" binding:"required"`
 	Status        string                 `json:"status" binding:"required"`
 	ReportData    map[string]interface{} `json:"report_data" binding:"required"`
+	Version       *int64                 `json:"version"`
 }
@@ -0,0 +1,18 @@
+package response
+
+type AcceptClassroomReportResponse4Web struct {
+	Accepted int                         `json:"accepted"`
+	Skipped  int                         `json:"skipped"`
+	Failed   int                         `json:"failed"`
+	Results  []AcceptStudentReportResult `json:"results"`
+}
+
+type AcceptStudentReportResult struct {
+	StudentID        string   `json:"student_id"`
+	TeacherID        string   `json:"teacher_id"`
+	ReportID         string   `json:"report_id,omitempty"`
+	Result           string   `json:"result"`
+	AcceptedSections []string `json:"accepted_sections,omitempty"`
+	SkippedSections  []string `json:"skipped_sections,omitempty"`
+	Reason           string   `json:"reason,omitempty"`
+}
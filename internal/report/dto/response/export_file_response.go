@@ -0,0 +1,10 @@
+package response
+
+// ExportFile: file đã render, handler trả thẳng về client
+type ExportFile struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+	// Inline: mở trực tiếp trên trình duyệt (vd HTML để in) thay vì tải về
+	Inline bool
+}
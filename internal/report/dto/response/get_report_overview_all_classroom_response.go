@@ -11,6 +11,9 @@ type AllClassroomTopicStatus struct {
 	Before            float32 `json:"before"`
 	Now               float32 `json:"now"`
 	Conclusion        float32 `json:"conclusion"`
+
+	// trung bình status của section tuỳ biến có track_progress, theo key
+	Sections map[string]float32 `json:"sections,omitempty"`
 }
 
 type ClassOverview struct {
@@ -1,6 +1,22 @@
 package response
 
 type GetReport2Print struct {
+	Language           string `json:"language"`
+	OrganizationName   string `json:"organization_name"`
+	OrganizationAvatar string `json:"organization_avatar"`
+	StudentName        string `json:"student_name"`
+	TeacherName        string `json:"teacher_name"`
+	TopicTitle         string `json:"topic_title"`
+	TopicImage         string `json:"topic_image"`
+	TermTitle          string `json:"term_title"`
+	Before             string `json:"before"`
+	Now                string `json:"now"`
+	Conclusion         string `json:"conclusion"`
+	// Comments: nhận xét của manager, chỉ có khi được yêu cầu
+	Comments *SectionComments `json:"comments,omitempty"`
+}
+
+type SectionComments struct {
 	Before     string `json:"before"`
 	Now        string `json:"now"`
 	Conclusion string `json:"conclusion"`
@@ -1,13 +1,21 @@
 package response
 
-import "report-service/pkg/constants"
+import (
+	"report-service/pkg/constants"
+	"time"
+)
 
 type GetTeacherReportTasksResponse4App struct {
-	Term        string                      `json:"term"`
-	Topic       string                      `json:"topic"`
-	StudentName string                      `json:"student_name"`
-	Deadline    string                      `json:"deadline"`
-	Task        constants.TeacherReportTask `json:"task"`
-	Status      string                      `json:"status"`
-	Language    string                      `json:"language"`
+	Term         string                      `json:"term"`
+	Topic        string                      `json:"topic"`
+	StudentName  string                      `json:"student_name"`
+	Deadline     string                      `json:"deadline"`
+	DueAt        *time.Time                  `json:"due_at,omitempty"`
+	Overdue      bool                        `json:"overdue"`
+	Task         constants.TeacherReportTask `json:"task"`
+	TaskTitle    string                      `json:"task_title,omitempty"`
+	Status       string                      `json:"status"`
+	Language     string                      `json:"language"`
+	Returned     bool                        `json:"returned"`
+	ReturnReason string                      `json:"return_reason,omitempty"`
 }
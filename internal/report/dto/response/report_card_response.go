@@ -0,0 +1,32 @@
+package response
+
+import (
+	"report-service/internal/report/model"
+	"time"
+)
+
+type ReportCardTopic4Web struct {
+	Topic       Topic                        `json:"topic"`
+	ReportID    string                       `json:"report_id"`
+	TeacherName string                       `json:"teacher_name"`
+	Status      string                       `json:"status"`
+	Before      string                       `json:"before"`
+	Now         string                       `json:"now"`
+	Conclusion  string                       `json:"conclusion"`
+	Translation *model.ReportTranslationData `json:"translation,omitempty"`
+	Comments    *SectionComments             `json:"comments,omitempty"`
+}
+
+// ReportCard4Web: phiếu báo cáo cả term của một học sinh, các topic theo thứ tự tên topic
+type ReportCard4Web struct {
+	OrganizationName   string                `json:"organization_name"`
+	OrganizationAvatar string                `json:"organization_avatar"`
+	StudentID          string                `json:"student_id"`
+	StudentName        string                `json:"student_name"`
+	TermID             string                `json:"term_id"`
+	TermTitle          string                `json:"term_title"`
+	Language           string                `json:"language"`
+	TargetLanguage     string                `json:"target_language,omitempty"`
+	Topics             []ReportCardTopic4Web `json:"topics"`
+	GeneratedAt        time.Time             `json:"generated_at"`
+}
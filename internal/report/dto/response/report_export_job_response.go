@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type ReportExportJob4Web struct {
+	ID           string     `json:"id"`
+	ClassroomID  string     `json:"classroom_id"`
+	TermID       string     `json:"term_id"`
+	TopicID      string     `json:"topic_id,omitempty"`
+	Language     string     `json:"language"`
+	Format       string     `json:"format"`
+	Status       string     `json:"status"`
+	Total        int        `json:"total"`
+	Processed    int        `json:"processed"`
+	Failed       int        `json:"failed"`
+	Progress     float32    `json:"progress"` // phần trăm 0..100
+	Error        string     `json:"error,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	ArtifactSize int64      `json:"artifact_size,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
@@ -1,11 +1,36 @@
 package response
 
-import "time"
+import (
+	"report-service/internal/report/diff"
+	"report-service/internal/report/model"
+	"time"
+)
 
 type ReportHistoryResponse struct {
-	ID        string         `json:"id"`
-	ReportID  string         `json:"report_id"`
-	EditorID  string         `json:"editor_id"`
-	Report    ReportResponse `json:"report,omitempty"`
-	Timestamp time.Time      `json:"timestamp"`
+	ID          string                    `json:"id"`
+	ReportID    string                    `json:"report_id"`
+	StudentID   string                    `json:"student_id,omitempty"`
+	ClassroomID string                    `json:"classroom_id,omitempty"`
+	Type        string                    `json:"type"`
+	EditorID    string                    `json:"editor_id"`
+	EditorRole  string                    `json:"editor_role"`
+	Report      ReportResponse            `json:"report,omitempty"`
+	Transitions []model.SectionTransition `json:"transitions,omitempty"`
+	Timestamp   time.Time                 `json:"timestamp"`
+}
+
+type ReportHistoryDiffResponse struct {
+	ID              string             `json:"id"`
+	ReportID        string             `json:"report_id"`
+	Type            string             `json:"type"`
+	EditorID        string             `json:"editor_id"`
+	EditorRole      string             `json:"editor_role"`
+	SourceHistoryID string             `json:"source_history_id,omitempty"`
+	Timestamp       time.Time          `json:"timestamp"`
+	Changes         []diff.FieldChange `json:"changes"`
+}
+
+type SearchReportHistoryResponse4Web struct {
+	Items      []ReportHistoryResponse `json:"items"`
+	NextCursor string                  `json:"next_cursor"`
 }
@@ -7,19 +7,23 @@ import (
 )
 
 type ReportResponse struct {
-	ID                         string                      `json:"id"`
-	StudentID                  string                      `json:"student_id"`
-	TopicID                    string                      `json:"topic_id"`
-	TermID                     string                      `json:"term_id"`
-	Editor                     gw_response.TeacherResponse `json:"editor,omitempty"`
-	Language                   string                      `json:"language"`
-	Status                     string                      `json:"status"`
-	Editing                    bool                        `json:"editing"`
-	ReportData                 map[string]interface{}      `json:"report_data"`
-	CreatedAt                  time.Time                   `json:"created_at"`
-	ManagerCommentPreviousTerm ManagerCommentPreviousTerm  `json:"manager_comment_previous_term"`
-	TeacherReportPreviousTerm  TeacherReportPreviousTerm   `json:"teacher_report_previous_term"`
-	LatestDataTermID           string                      `json:"latest_data_term_id"`
+	ID                         string                           `json:"id"`
+	StudentID                  string                           `json:"student_id"`
+	TopicID                    string                           `json:"topic_id"`
+	TermID                     string                           `json:"term_id"`
+	Editor                     gw_response.TeacherResponse      `json:"editor,omitempty"`
+	Language                   string                           `json:"language"`
+	Status                     string                           `json:"status"`
+	Editing                    bool                             `json:"editing"`
+	EditingLock                *model.EditingLock               `json:"editing_lock"`
+	ReportData                 map[string]interface{}           `json:"report_data"`
+	Rejections                 []model.SectionRejection         `json:"rejections"`
+	Version                    int64                            `json:"version"`
+	CreatedAt                  time.Time                        `json:"created_at"`
+	ManagerCommentPreviousTerm ManagerCommentPreviousTerm       `json:"manager_comment_previous_term"`
+	TeacherReportPreviousTerm  TeacherReportPreviousTerm        `json:"teacher_report_previous_term"`
+	LatestDataTermID           string                           `json:"latest_data_term_id"`
+	CustomSections             []*model.ReportSectionDefinition `json:"custom_sections,omitempty"`
 }
 
 type ReportEditor struct {
@@ -77,4 +81,7 @@ type ReportSummary struct {
 	Before         float32 `json:"before"`
 	Now            float32 `json:"now"`
 	Conclusion     float32 `json:"conclusion"`
+
+	// trung bình status của section tuỳ biến có track_progress, theo key
+	Sections map[string]float32 `json:"sections,omitempty"`
 }
@@ -0,0 +1,31 @@
+package response
+
+import "time"
+
+type ReviewQueueItem4Web struct {
+	ReportID       string    `json:"report_id"`
+	StudentID      string    `json:"student_id"`
+	StudentName    string    `json:"student_name"`
+	TeacherID      string    `json:"teacher_id"`
+	TeacherName    string    `json:"teacher_name"`
+	TopicID        string    `json:"topic_id"`
+	Language       string    `json:"language"`
+	Section        string    `json:"section"`
+	Status         string    `json:"status"`
+	Reason         string    `json:"reason"`
+	WaitingSince   time.Time `json:"waiting_since"`
+	WaitingMinutes int64     `json:"waiting_minutes"`
+}
+
+type ReviewQueueClassroom4Web struct {
+	ClassroomID   string                `json:"classroom_id"`
+	ClassroomName string                `json:"classroom_name"`
+	OldestWaiting *time.Time            `json:"oldest_waiting,omitempty"`
+	Items         []ReviewQueueItem4Web `json:"items"`
+}
+
+type ReviewQueueResponse4Web struct {
+	TermID     string                     `json:"term_id"`
+	Total      int                        `json:"total"`
+	Classrooms []ReviewQueueClassroom4Web `json:"classrooms"`
+}
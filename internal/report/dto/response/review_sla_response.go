@@ -0,0 +1,24 @@
+package response
+
+// DurationStats4Web: thời gian tính bằng phút
+type DurationStats4Web struct {
+	Count          int     `json:"count"`
+	AverageMinutes float64 `json:"average_minutes"`
+	MedianMinutes  float64 `json:"median_minutes"`
+	P90Minutes     float64 `json:"p90_minutes"`
+}
+
+type ReviewSLAGroup4Web struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Stats DurationStats4Web `json:"stats"`
+}
+
+type ReviewSLAResponse4Web struct {
+	TermID       string                       `json:"term_id"`
+	Overall      DurationStats4Web            `json:"overall"`
+	TimeInStatus map[string]DurationStats4Web `json:"time_in_status"`
+	Classrooms   []ReviewSLAGroup4Web         `json:"classrooms"`
+	Teachers     []ReviewSLAGroup4Web         `json:"teachers"`
+	Topics       []ReviewSLAGroup4Web         `json:"topics"`
+}
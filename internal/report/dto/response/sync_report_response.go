@@ -0,0 +1,35 @@
+package response
+
+import (
+	"report-service/internal/report/diff"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+)
+
+type SyncChangesResponse4App struct {
+	Reports   []ReportResponse `json:"reports"`
+	SyncToken string           `json:"sync_token"`
+	HasMore   bool             `json:"has_more"`
+}
+
+type SyncPushItemResult4App struct {
+	Index          int                      `json:"index"`
+	StudentID      string                   `json:"student_id"`
+	TopicID        string                   `json:"topic_id"`
+	TermID         string                   `json:"term_id"`
+	Language       string                   `json:"language"`
+	Result         constants.SyncPushResult `json:"result"`
+	ReportID       string                   `json:"report_id,omitempty"`
+	Version        int64                    `json:"version,omitempty"`
+	CurrentVersion *int64                   `json:"current_version,omitempty"`
+	Conflicts      []diff.MergeConflict     `json:"conflicts,omitempty"`
+	EditingLock    *model.EditingLock       `json:"editing_lock,omitempty"`
+	Error          string                   `json:"error,omitempty"`
+}
+
+type SyncPushResponse4App struct {
+	Results   []SyncPushItemResult4App `json:"results"`
+	Applied   int                      `json:"applied"`
+	Conflicts int                      `json:"conflicts"`
+	Failed    int                      `json:"failed"`
+}
@@ -0,0 +1,6 @@
+package response
+
+// UploadReportResponse: version mới của report sau khi lưu, client gửi lại ở lần lưu kế tiếp
+type UploadReportResponse struct {
+	Version int64 `json:"version"`
+}
@@ -0,0 +1,73 @@
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+// Broker phân phối ReportEvent tới subscriber. Bản in-process dùng cho 1 instance,
+// chạy nhiều instance thì thay bằng implementation qua message bus với cùng interface.
+type Broker interface {
+	Publish(ctx context.Context, e ReportEvent) error
+	Subscribe(scope Scope) *Subscription
+}
+
+// Subscription nhận event qua C, gọi Close khi client ngắt kết nối
+type Subscription struct {
+	C <-chan ReportEvent
+
+	ch    chan ReportEvent
+	scope Scope
+	once  sync.Once
+	close func()
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(s.close)
+}
+
+// số event tối đa chờ gửi cho mỗi subscriber, client chậm hơn sẽ bị bỏ event
+const subscriptionBuffer = 64
+
+type memoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+}
+
+func NewMemoryBroker() Broker {
+	return &memoryBroker{subscribers: make(map[*Subscription]struct{})}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, e ReportEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers {
+		if !sub.scope.Match(e) {
+			continue
+		}
+		// không block luồng ghi report vì một client chậm
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(scope Scope) *Subscription {
+	ch := make(chan ReportEvent, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, scope: scope}
+	sub.close = func() {
+		b.mu.Lock()
+		delete(b.subscribers, sub)
+		b.mu.Unlock()
+		close(ch)
+	}
+
+	b.mu.Lock()
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	return sub
+}
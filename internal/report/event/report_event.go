@@ -0,0 +1,93 @@
+package event
+
+import (
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"time"
+)
+
+// ReportEvent là thay đổi của một report được đẩy tới client đang theo dõi (SSE)
+type ReportEvent struct {
+	Type        constants.ReportEventType `json:"type"`
+	ReportID    string                    `json:"report_id"`
+	StudentID   string                    `json:"student_id"`
+	TopicID     string                    `json:"topic_id"`
+	TermID      string                    `json:"term_id"`
+	Language    string                    `json:"language"`
+	Version     int64                     `json:"version"`
+	Status      string                    `json:"status,omitempty"`
+	HistoryType string                    `json:"history_type,omitempty"`
+	EditorID    string                    `json:"editor_id,omitempty"`
+	EditorRole  string                    `json:"editor_role,omitempty"`
+	Transitions []model.SectionTransition `json:"transitions,omitempty"`
+	EditingLock *model.EditingLock        `json:"editing_lock,omitempty"`
+	Timestamp   time.Time                 `json:"timestamp"`
+}
+
+// FromHistory tạo event từ history vừa lưu, có transition thì là status_changed
+func FromHistory(history *model.ReportHistory) ReportEvent {
+	e := ReportEvent{
+		Type:        constants.ReportEventSaved,
+		ReportID:    history.ReportID.Hex(),
+		StudentID:   history.StudentID,
+		HistoryType: history.Type,
+		EditorID:    history.EditorID,
+		EditorRole:  history.EditorRole,
+		Transitions: history.Transitions,
+		Version:     history.Version,
+		Timestamp:   history.Timestamp,
+	}
+	if len(history.Transitions) > 0 {
+		e.Type = constants.ReportEventStatusChanged
+	}
+	if history.Report != nil {
+		e.TopicID = history.Report.TopicID
+		e.TermID = history.Report.TermID
+		e.Language = history.Report.Language
+		e.Status = history.Report.Status
+		e.EditingLock = history.Report.Lock
+	}
+	return e
+}
+
+// FromLock tạo event khi lock chỉnh sửa của report thay đổi (lock nil = đã release)
+func FromLock(report *model.Report, lock *model.EditingLock) ReportEvent {
+	return ReportEvent{
+		Type:        constants.ReportEventLockChanged,
+		ReportID:    report.ID.Hex(),
+		StudentID:   report.StudentID,
+		TopicID:     report.TopicID,
+		TermID:      report.TermID,
+		Language:    report.Language,
+		Version:     report.Version,
+		Status:      report.Status,
+		EditingLock: lock,
+		Timestamp:   time.Now(),
+	}
+}
+
+// Scope giới hạn event theo term/topic/language và danh sách học sinh (vd: học sinh của một classroom)
+type Scope struct {
+	TermID     string
+	TopicID    string
+	Language   string
+	StudentIDs map[string]struct{}
+}
+
+func (s Scope) Match(e ReportEvent) bool {
+	if s.TermID != "" && s.TermID != e.TermID {
+		return false
+	}
+	if s.TopicID != "" && s.TopicID != e.TopicID {
+		return false
+	}
+	if s.Language != "" && s.Language != e.Language {
+		return false
+	}
+	if s.StudentIDs != nil {
+		if _, ok := s.StudentIDs[e.StudentID]; !ok {
+			return false
+		}
+	}
+	return true
+}
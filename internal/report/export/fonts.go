@@ -0,0 +1,27 @@
+package export
+
+import (
+	"os"
+	"report-service/pkg/pdf"
+)
+
+// LoadFonts đọc font TTF theo config, regularPath rỗng = dùng font chuẩn của PDF
+func LoadFonts(regularPath, boldPath string) (*pdf.Fonts, error) {
+	if regularPath == "" {
+		return nil, nil
+	}
+
+	regular, err := os.ReadFile(regularPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var bold []byte
+	if boldPath != "" {
+		if bold, err = os.ReadFile(boldPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return pdf.LoadFonts(regular, bold)
+}
@@ -0,0 +1,42 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxImageSize giới hạn ảnh tải về để không giữ file quá lớn trong bộ nhớ
+const maxImageSize = 5 << 20
+
+var imageClient = &http.Client{Timeout: 10 * time.Second}
+
+// FetchImage tải và decode ảnh (jpeg, png, gif) từ url
+func FetchImage(ctx context.Context, url string) (image.Image, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := imageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch image failed with status %d", resp.StatusCode)
+	}
+
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
@@ -0,0 +1,56 @@
+package export
+
+import "strings"
+
+// labels: nhãn cố định trên file xuất, theo ngôn ngữ của report
+type labels struct {
+	Title      string
+	CardTitle  string
+	Student    string
+	Teacher    string
+	Topic      string
+	Term       string
+	Before     string
+	Now        string
+	Conclusion string
+	Comment    string
+	Page       string
+	NoReports  string
+}
+
+var englishLabels = labels{
+	Title:      "Student report",
+	CardTitle:  "Term report card",
+	Student:    "Student",
+	Teacher:    "Teacher",
+	Topic:      "Topic",
+	Term:       "Term",
+	Before:     "Before",
+	Now:        "Now",
+	Conclusion: "Conclusion",
+	Comment:    "Manager comment",
+	Page:       "Page",
+	NoReports:  "No reports for this term yet.",
+}
+
+var vietnameseLabels = labels{
+	Title:      "Báo cáo học sinh",
+	CardTitle:  "Phiếu báo cáo học kỳ",
+	Student:    "Học sinh",
+	Teacher:    "Giáo viên",
+	Topic:      "Chủ đề",
+	Term:       "Học kỳ",
+	Before:     "Trước đây",
+	Now:        "Hiện tại",
+	Conclusion: "Kết luận",
+	Comment:    "Nhận xét của quản lý",
+	Page:       "Trang",
+	NoReports:  "Chưa có báo cáo nào trong học kỳ này.",
+}
+
+func labelsFor(language string) labels {
+	if strings.HasPrefix(strings.ToLower(language), "vi") {
+		return vietnameseLabels
+	}
+	return englishLabels
+}
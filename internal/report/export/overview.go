@@ -0,0 +1,191 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"report-service/internal/report/dto/response"
+	"report-service/pkg/xlsx"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mỗi topic chiếm 4 cột trong bảng overview
+var overviewMetrics = []string{"Before", "Now", "Conclusion", "Main"}
+
+// OverviewTable: bảng dạng ma trận dòng (lớp hoặc học sinh) x topic,
+// mỗi ô topic gồm before/now/conclusion/main percentage
+type OverviewTable struct {
+	Sheet  string
+	Header []string
+	Rows   [][]interface{}
+	// số cột đầu là thông tin dòng (tên lớp, học sinh...), cố định khi cuộn
+	labelColumns int
+}
+
+type overviewTopic struct {
+	ID    string
+	Title string
+}
+
+// ClassroomOverviewTable: ma trận lớp x topic của GetReportOverViewAllClassroom4Web.
+// Chỉ lấy topic có dữ liệu, theo thứ tự all_topics của organization.
+func ClassroomOverviewTable(res *response.GetReportOverviewAllClassroomResponse4Web) *OverviewTable {
+	var rows [][]response.AllClassroomTopicStatus
+	for _, class := range res.Classes {
+		rows = append(rows, class.Topics)
+	}
+
+	order := make([]overviewTopic, 0, len(res.AllTopics))
+	for _, t := range res.AllTopics {
+		order = append(order, overviewTopic{ID: t.ID, Title: t.Title})
+	}
+	topics := overviewTopics(order, rows)
+
+	table := newOverviewTable("Classrooms", []string{"Classroom", "Average %"}, topics)
+	for _, class := range res.Classes {
+		table.addRow([]interface{}{class.ClassName, round2(class.AverageTopicsPercentage)}, topics, class.Topics)
+	}
+	table.Rows = append(table.Rows, []interface{}{"Overall", round2(res.OverallClassesPercentage)})
+	return table
+}
+
+// StudentOverviewTable: ma trận học sinh x topic của GetReportOverViewByClassroom4Web
+func StudentOverviewTable(res *response.GetReportOverviewByClassroomResponse4Web) *OverviewTable {
+	var rows [][]response.AllClassroomTopicStatus
+	for _, student := range res.ClassOverview {
+		rows = append(rows, student.Topics)
+	}
+	topics := overviewTopics(nil, rows)
+
+	table := newOverviewTable(res.ClassInfo.ClassName, []string{"Student", "Student ID", "Teacher", "Average %"}, topics)
+	for _, student := range res.ClassOverview {
+		table.addRow([]interface{}{student.Student.Name, student.Student.ID, student.Teacher.Name, round2(student.AverageTopicsPercentage)}, topics, student.Topics)
+	}
+	table.Rows = append(table.Rows, []interface{}{"Overall", nil, nil, round2(res.OverallClassPercentage)})
+	return table
+}
+
+func newOverviewTable(sheet string, labels []string, topics []overviewTopic) *OverviewTable {
+	header := append([]string{}, labels...)
+	for _, t := range topics {
+		for _, metric := range overviewMetrics {
+			header = append(header, t.Title+" - "+metric)
+		}
+	}
+	return &OverviewTable{Sheet: sheet, Header: header, labelColumns: len(labels)}
+}
+
+// addRow: topic không có report thì để ô trống, phân biệt với 0%
+func (t *OverviewTable) addRow(labels []interface{}, topics []overviewTopic, statuses []response.AllClassroomTopicStatus) {
+	byID := make(map[string]response.AllClassroomTopicStatus, len(statuses))
+	for _, s := range statuses {
+		byID[s.TopicID] = s
+	}
+
+	row := append([]interface{}{}, labels...)
+	for _, topic := range topics {
+		s, ok := byID[topic.ID]
+		if !ok {
+			row = append(row, nil, nil, nil, nil)
+			continue
+		}
+		row = append(row, round2(s.Before), round2(s.Now), round2(s.Conclusion), round2(s.MainPercentage))
+	}
+	t.Rows = append(t.Rows, row)
+}
+
+// overviewTopics: các topic xuất hiện trong rows, theo thứ tự order rồi tới topic còn lại theo tên
+func overviewTopics(order []overviewTopic, rows [][]response.AllClassroomTopicStatus) []overviewTopic {
+	seen := make(map[string]string)
+	for _, statuses := range rows {
+		for _, s := range statuses {
+			if seen[s.TopicID] == "" {
+				seen[s.TopicID] = s.TopicTitle
+			}
+		}
+	}
+
+	topics := make([]overviewTopic, 0, len(seen))
+	for _, t := range order {
+		if _, ok := seen[t.ID]; ok {
+			topics = append(topics, t)
+			delete(seen, t.ID)
+		}
+	}
+
+	rest := make([]overviewTopic, 0, len(seen))
+	for id, title := range seen {
+		if title == "" {
+			title = id
+		}
+		rest = append(rest, overviewTopic{ID: id, Title: title})
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		a, b := strings.ToLower(rest[i].Title), strings.ToLower(rest[j].Title)
+		if a != b {
+			return a < b
+		}
+		return rest[i].ID < rest[j].ID
+	})
+	return append(topics, rest...)
+}
+
+func round2(v float32) float64 {
+	return math.Round(float64(v)*100) / 100
+}
+
+// CSV: có BOM để Excel mở đúng tiếng Việt
+func (t *OverviewTable) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xef\xbb\xbf")
+
+	w := csv.NewWriter(&buf)
+	if err := w.Write(t.Header); err != nil {
+		return nil, err
+	}
+	for _, row := range t.Rows {
+		record := make([]string, len(t.Header))
+		for i, v := range row {
+			switch v := v.(type) {
+			case nil:
+			case string:
+				record[i] = v
+			case float64:
+				record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *OverviewTable) XLSX() ([]byte, error) {
+	wb := xlsx.New()
+	sheet := wb.AddSheet(t.Sheet)
+
+	header := make([]interface{}, len(t.Header))
+	for i, h := range t.Header {
+		header[i] = h
+	}
+	sheet.Header(header...)
+	for _, row := range t.Rows {
+		sheet.Row(row...)
+	}
+
+	sheet.Freeze(1, t.labelColumns)
+	for i := 0; i < t.labelColumns; i++ {
+		sheet.SetColumnWidth(i, 24)
+	}
+	for i := t.labelColumns; i < len(t.Header); i++ {
+		sheet.SetColumnWidth(i, 16)
+	}
+	return wb.Bytes()
+}
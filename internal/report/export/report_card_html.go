@@ -0,0 +1,184 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+	"report-service/internal/report/dto/response"
+	"strings"
+)
+
+type reportCardSection struct {
+	Title   string
+	Content string
+	Comment string
+}
+
+type reportCardTopic struct {
+	Title       string
+	Image       string
+	TeacherName string
+	Sections    []reportCardSection
+}
+
+type reportCardView struct {
+	Title  string
+	Lang   string
+	Labels labels
+	Card   *response.ReportCard4Web
+	Topics []reportCardTopic
+}
+
+var reportCardTemplate = template.Must(template.New("report_card").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - {{.Card.StudentName}}</title>
+<style>
+@page { size: A4; margin: 18mm; }
+body { font-family: "DejaVu Sans", Arial, sans-serif; font-size: 11pt; color: #222; margin: 0; }
+header { display: flex; align-items: center; gap: 12px; border-bottom: 1px solid #ccc; padding-bottom: 10px; }
+header img { width: 48px; height: 48px; object-fit: cover; border-radius: 6px; }
+header .org { font-size: 16pt; font-weight: bold; }
+header .term { color: #666; }
+h1 { font-size: 18pt; margin: 18px 0 6px; }
+.info { margin-bottom: 12px; }
+.topic { border-top: 1px solid #eee; padding-top: 12px; margin-top: 16px; }
+.topic h2 { font-size: 14pt; margin: 0 0 4px; break-after: avoid; }
+.topic .teacher { color: #666; margin-bottom: 8px; }
+.topic img { max-width: 100%; max-height: 200px; margin-bottom: 8px; }
+.topic h3 { font-size: 12pt; margin: 10px 0 2px; break-after: avoid; }
+.topic p { margin: 0; white-space: pre-wrap; }
+.topic p.comment { margin-top: 4px; color: #555; font-style: italic; }
+</style>
+</head>
+<body>
+<header>
+{{if .Card.OrganizationAvatar}}<img src="{{.Card.OrganizationAvatar}}" alt="">{{end}}
+<div>
+<div class="org">{{.Card.OrganizationName}}</div>
+<div class="term">{{.Card.TermTitle}}</div>
+</div>
+</header>
+<h1>{{.Title}}</h1>
+<div class="info">
+<div>{{.Labels.Student}}: <strong>{{.Card.StudentName}}</strong></div>
+{{if .Card.TermTitle}}<div>{{.Labels.Term}}: {{.Card.TermTitle}}</div>{{end}}
+</div>
+{{range .Topics}}
+<section class="topic">
+<h2>{{.Title}}</h2>
+{{if .TeacherName}}<div class="teacher">{{$.Labels.Teacher}}: {{.TeacherName}}</div>{{end}}
+{{if .Image}}<img src="{{.Image}}" alt="">{{end}}
+{{range .Sections}}
+<h3>{{.Title}}</h3>
+<p>{{.Content}}</p>
+{{if .Comment}}<p class="comment">{{$.Labels.Comment}}: {{.Comment}}</p>{{end}}
+{{end}}
+</section>
+{{else}}
+<p>{{.Labels.NoReports}}</p>
+{{end}}
+</body>
+</html>
+`))
+
+// RenderReportCardHTML dựng trang HTML để in phiếu báo cáo,
+// có bản dịch thì dùng bản dịch, field dịch còn trống thì giữ nội dung gốc
+func RenderReportCardHTML(card *response.ReportCard4Web) ([]byte, error) {
+	lang := card.Language
+	if card.TargetLanguage != "" {
+		lang = card.TargetLanguage
+	}
+	l := labelsFor(lang)
+
+	view := reportCardView{
+		Title:  l.CardTitle,
+		Lang:   lang,
+		Labels: l,
+		Card:   card,
+		Topics: make([]reportCardTopic, 0, len(card.Topics)),
+	}
+	for _, t := range card.Topics {
+		view.Topics = append(view.Topics, reportCardTopic{
+			Title:       t.Topic.Title,
+			Image:       t.Topic.MainImageUrl,
+			TeacherName: t.TeacherName,
+			Sections:    cardTopicSections(l, t),
+		})
+	}
+
+	return executeReportCard(view)
+}
+
+// RenderReportHTML dựng trang HTML để in một report, dùng chung mẫu với phiếu báo cáo
+func RenderReportHTML(data *response.GetReport2Print) ([]byte, error) {
+	l := labelsFor(data.Language)
+	card := &response.ReportCard4Web{
+		OrganizationName:   data.OrganizationName,
+		OrganizationAvatar: data.OrganizationAvatar,
+		StudentName:        data.StudentName,
+		TermTitle:          data.TermTitle,
+		Language:           data.Language,
+	}
+
+	return executeReportCard(reportCardView{
+		Title:  l.Title,
+		Lang:   data.Language,
+		Labels: l,
+		Card:   card,
+		Topics: []reportCardTopic{{
+			Title:       data.TopicTitle,
+			Image:       data.TopicImage,
+			TeacherName: data.TeacherName,
+			Sections:    reportSections(l, data.Before, data.Now, data.Conclusion, data.Comments),
+		}},
+	})
+}
+
+// cardTopicSections: các phần của một topic trong phiếu báo cáo, ưu tiên bản dịch
+func cardTopicSections(l labels, t response.ReportCardTopic4Web) []reportCardSection {
+	before, now, conclusion := t.Before, t.Now, t.Conclusion
+	if t.Translation != nil {
+		before = preferTranslation(t.Translation.Before, before)
+		now = preferTranslation(t.Translation.Now, now)
+		conclusion = preferTranslation(t.Translation.Conclusion, conclusion)
+	}
+	return reportSections(l, before, now, conclusion, t.Comments)
+}
+
+// reportSections: before, now, conclusion kèm nhận xét của manager (nếu có)
+func reportSections(l labels, before, now, conclusion string, comments *response.SectionComments) []reportCardSection {
+	sections := []reportCardSection{
+		{Title: l.Before, Content: orDash(before)},
+		{Title: l.Now, Content: orDash(now)},
+		{Title: l.Conclusion, Content: orDash(conclusion)},
+	}
+	if comments != nil {
+		sections[0].Comment = strings.TrimSpace(comments.Before)
+		sections[1].Comment = strings.TrimSpace(comments.Now)
+		sections[2].Comment = strings.TrimSpace(comments.Conclusion)
+	}
+	return sections
+}
+
+func executeReportCard(view reportCardView) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportCardTemplate.Execute(&buf, view); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func preferTranslation(translated, original string) string {
+	if strings.TrimSpace(translated) != "" {
+		return translated
+	}
+	return original
+}
+
+func orDash(s string) string {
+	if s = strings.TrimSpace(s); s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package export
+
+import (
+	"image"
+	"report-service/internal/report/dto/response"
+	"report-service/pkg/docx"
+)
+
+// kích thước ảnh trên file Word, theo pixel 96 dpi
+const (
+	docxAvatarSize     = 64
+	docxTopicMaxWidth  = 600
+	docxTopicMaxHeight = 300
+)
+
+// CardImages: ảnh đã tải sẵn của phiếu báo cáo, ảnh topic theo topic id
+type CardImages struct {
+	OrganizationAvatar image.Image
+	Topics             map[string]image.Image
+}
+
+// RenderReportDOCX dựng file Word của một report, cùng bố cục với bản PDF
+// để văn phòng chỉnh sửa tiếp trước khi in
+func RenderReportDOCX(data *response.GetReport2Print, images Images) ([]byte, error) {
+	l := labelsFor(data.Language)
+	doc := docx.New()
+
+	if err := docxHeader(doc, data.OrganizationName, data.TermTitle, images.OrganizationAvatar); err != nil {
+		return nil, err
+	}
+	doc.Paragraph(l.Title, docx.Title)
+	for _, row := range [][2]string{
+		{l.Student, data.StudentName},
+		{l.Teacher, data.TeacherName},
+		{l.Topic, data.TopicTitle},
+		{l.Term, data.TermTitle},
+	} {
+		if row[1] != "" {
+			doc.Field(row[0], row[1])
+		}
+	}
+	if images.TopicImage != nil {
+		if err := doc.Image(images.TopicImage, docxTopicMaxWidth, docxTopicMaxHeight, "", docx.Normal); err != nil {
+			return nil, err
+		}
+	}
+
+	docxSections(doc, l, reportSections(l, data.Before, data.Now, data.Conclusion, data.Comments), docx.Heading1)
+	return doc.Bytes()
+}
+
+// RenderReportCardDOCX dựng file Word của phiếu báo cáo, mỗi topic là một heading
+// để dễ di chuyển/xoá trong Navigation pane
+func RenderReportCardDOCX(card *response.ReportCard4Web, images CardImages) ([]byte, error) {
+	lang := card.Language
+	if card.TargetLanguage != "" {
+		lang = card.TargetLanguage
+	}
+	l := labelsFor(lang)
+	doc := docx.New()
+
+	if err := docxHeader(doc, card.OrganizationName, card.TermTitle, images.OrganizationAvatar); err != nil {
+		return nil, err
+	}
+	doc.Paragraph(l.CardTitle, docx.Title)
+	doc.Field(l.Student, card.StudentName)
+	if card.TermTitle != "" {
+		doc.Field(l.Term, card.TermTitle)
+	}
+
+	if len(card.Topics) == 0 {
+		doc.Paragraph(l.NoReports, docx.Normal)
+	}
+	for _, t := range card.Topics {
+		doc.Paragraph(t.Topic.Title, docx.Heading1)
+		if t.TeacherName != "" {
+			doc.Paragraph(l.Teacher+": "+t.TeacherName, docx.Subtle)
+		}
+		if img := images.Topics[t.Topic.ID]; img != nil {
+			if err := doc.Image(img, docxTopicMaxWidth, docxTopicMaxHeight, "", docx.Normal); err != nil {
+				return nil, err
+			}
+		}
+		docxSections(doc, l, cardTopicSections(l, t), docx.Heading2)
+	}
+
+	return doc.Bytes()
+}
+
+// docxHeader: avatar và tên organization cùng một dòng, term ở dòng dưới
+func docxHeader(doc *docx.Document, organization, term string, avatar image.Image) error {
+	if avatar != nil {
+		if err := doc.Image(avatar, docxAvatarSize, docxAvatarSize, organization, docx.Strong); err != nil {
+			return err
+		}
+	} else if organization != "" {
+		doc.Paragraph(organization, docx.Strong)
+	}
+	if term != "" {
+		doc.Paragraph(term, docx.Subtle)
+	}
+	return nil
+}
+
+func docxSections(doc *docx.Document, l labels, sections []reportCardSection, heading docx.Style) {
+	for _, section := range sections {
+		doc.Paragraph(section.Title, heading)
+		doc.Paragraph(section.Content, docx.Normal)
+		if section.Comment != "" {
+			doc.Paragraph(l.Comment+": "+section.Comment, docx.Subtle)
+		}
+	}
+}
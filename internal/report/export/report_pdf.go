@@ -0,0 +1,74 @@
+package export
+
+import (
+	"fmt"
+	"image"
+	"report-service/internal/report/dto/response"
+	"report-service/pkg/pdf"
+)
+
+const (
+	pageMargin = 48
+	avatarSize = 48
+)
+
+// Images: ảnh đã tải sẵn, nil = không có hoặc tải lỗi
+type Images struct {
+	OrganizationAvatar image.Image
+	TopicImage         image.Image
+}
+
+// RenderReportPDF dựng PDF của một report: header organization, thông tin học sinh/topic
+// rồi lần lượt các phần before, now, conclusion
+func RenderReportPDF(data *response.GetReport2Print, images Images, fonts *pdf.Fonts) ([]byte, error) {
+	l := labelsFor(data.Language)
+	doc := pdf.New(fonts, pageMargin)
+	doc.SetFooter(func(page, total int) string {
+		return fmt.Sprintf("%s %d / %d", l.Page, page, total)
+	})
+
+	// header: avatar bên trái, tên organization bên phải
+	top := doc.Y()
+	textX := float64(pageMargin)
+	if images.OrganizationAvatar != nil {
+		doc.ImageAt(images.OrganizationAvatar, pageMargin, top, avatarSize, avatarSize)
+		textX += avatarSize + 12
+	}
+	doc.TextAt(textX, top+8, data.OrganizationName, pdf.Bold, 16)
+	doc.TextAt(textX, top+30, data.TermTitle, pdf.Regular, 10)
+	doc.SetY(top + avatarSize + 12)
+	doc.Line()
+	doc.Space(16)
+
+	doc.Paragraph(l.Title, pdf.Bold, 18)
+	doc.Space(6)
+	for _, row := range [][2]string{
+		{l.Student, data.StudentName},
+		{l.Teacher, data.TeacherName},
+		{l.Topic, data.TopicTitle},
+		{l.Term, data.TermTitle},
+	} {
+		if row[1] == "" {
+			continue
+		}
+		doc.Paragraph(row[0]+": "+row[1], pdf.Regular, 11)
+	}
+
+	if images.TopicImage != nil {
+		doc.Space(10)
+		doc.Image(images.TopicImage, doc.ContentWidth(), 220)
+	}
+
+	for _, section := range reportSections(l, data.Before, data.Now, data.Conclusion, data.Comments) {
+		doc.Space(18)
+		doc.Paragraph(section.Title, pdf.Bold, 13)
+		doc.Space(4)
+		doc.Paragraph(section.Content, pdf.Regular, 11)
+		if section.Comment != "" {
+			doc.Space(4)
+			doc.Paragraph(l.Comment+": "+section.Comment, pdf.Regular, 10)
+		}
+	}
+
+	return doc.Bytes()
+}
@@ -0,0 +1,242 @@
+package exportjob
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/logger"
+	"report-service/pkg/blobstore"
+	"report-service/pkg/constants"
+	"time"
+)
+
+var ErrQueueFull = errors.New("report export queue is full")
+
+// trạng thái của từng dòng trong manifest.csv
+const (
+	entryOK      = "ok"
+	entryMissing = "missing"
+	entryFailed  = "failed"
+)
+
+// Entry: một file trong ZIP, Render nil = học sinh chưa có report cho topic này
+type Entry struct {
+	StudentID   string
+	StudentName string
+	TopicID     string
+	TopicTitle  string
+	FileName    string
+	Render      func(ctx context.Context) ([]byte, error)
+}
+
+// Source liệt kê các file cần đóng gói của một job
+type Source interface {
+	Entries(ctx context.Context, job *model.ReportExportJob) ([]Entry, error)
+}
+
+type Config struct {
+	Workers   int
+	QueueSize int
+	Timeout   time.Duration
+}
+
+type task struct {
+	ctx    context.Context
+	job    *model.ReportExportJob
+	source Source
+}
+
+// Queue chạy job export trong bộ nhớ bằng một nhóm worker, tiến độ được ghi vào repo
+type Queue struct {
+	repo  repository.ReportExportJobRepository
+	store blobstore.Store
+	cfg   Config
+	tasks chan task
+}
+
+func NewQueue(repo repository.ReportExportJobRepository, store blobstore.Store, cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Minute
+	}
+	return &Queue{
+		repo:  repo,
+		store: store,
+		cfg:   cfg,
+		tasks: make(chan task, cfg.QueueSize),
+	}
+}
+
+// Submit đưa job (đã lưu) vào hàng đợi. Context của request được giữ lại giá trị
+// (token, current user) để worker gọi gateway, nhưng không bị huỷ khi request kết thúc.
+func (q *Queue) Submit(ctx context.Context, job *model.ReportExportJob, source Source) error {
+	select {
+	case q.tasks <- task{ctx: context.WithoutCancel(ctx), job: job, source: source}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start chạy các worker, dừng khi ctx bị huỷ
+func (q *Queue) Start(ctx context.Context) {
+	for i := 0; i < q.cfg.Workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case t := <-q.tasks:
+					q.run(t)
+				}
+			}
+		}()
+	}
+	<-ctx.Done()
+}
+
+func (q *Queue) run(t task) {
+	job := t.job
+	ctx, cancel := context.WithTimeout(t.ctx, q.cfg.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			q.fail(t.ctx, job, fmt.Errorf("export panic: %v", r))
+		}
+	}()
+
+	now := time.Now()
+	job.Status = string(constants.ReportExportJobRunning)
+	job.StartedAt = &now
+	q.save(t.ctx, job)
+
+	if err := q.export(ctx, job, t.source); err != nil {
+		q.fail(t.ctx, job, err)
+		return
+	}
+
+	finished := time.Now()
+	job.Status = string(constants.ReportExportJobCompleted)
+	job.FinishedAt = &finished
+	q.save(t.ctx, job)
+}
+
+func (q *Queue) export(ctx context.Context, job *model.ReportExportJob, source Source) error {
+	entries, err := source.Entries(ctx, job)
+	if err != nil {
+		return err
+	}
+	job.Total = len(entries)
+	q.save(ctx, job)
+
+	tmp, err := os.CreateTemp("", "report-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	manifest := [][]string{{"student_id", "student_name", "topic_id", "topic_title", "file", "status", "error"}}
+
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status, file, msg := entryMissing, "", ""
+		if e.Render != nil {
+			if data, err := e.Render(ctx); err != nil {
+				status, msg = entryFailed, err.Error()
+				job.Failed++
+			} else if err := writeZipFile(zw, e.FileName, data); err != nil {
+				return err
+			} else {
+				status, file = entryOK, e.FileName
+			}
+		}
+		manifest = append(manifest, []string{e.StudentID, e.StudentName, e.TopicID, e.TopicTitle, file, status, msg})
+
+		job.Processed++
+		q.save(ctx, job)
+	}
+
+	if err := writeManifest(zw, manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("report-exports/%s/%s.zip", job.OrganizationID, job.ID.Hex())
+	size, err := q.store.Put(ctx, key, tmp)
+	if err != nil {
+		return err
+	}
+
+	job.ArtifactKey = key
+	job.ArtifactSize = size
+	job.FileName = fmt.Sprintf("reports_%s_%s.zip", job.ClassroomID, job.TermID)
+	return nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeManifest: có BOM để Excel mở đúng tiếng Việt
+func writeManifest(zw *zip.Writer, rows [][]string) error {
+	w, err := zw.Create("manifest.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func (q *Queue) fail(ctx context.Context, job *model.ReportExportJob, err error) {
+	logger.WriteLogEx("error", "report export job failed: "+err.Error(), map[string]interface{}{
+		"job_id": job.ID.Hex(),
+	})
+
+	now := time.Now()
+	job.Status = string(constants.ReportExportJobFailed)
+	job.Error = err.Error()
+	job.FinishedAt = &now
+	q.save(ctx, job)
+}
+
+// save: lỗi ghi tiến độ chỉ ghi log, job vẫn chạy tiếp
+func (q *Queue) save(ctx context.Context, job *model.ReportExportJob) {
+	if err := q.repo.Update(context.WithoutCancel(ctx), job); err != nil {
+		logger.WriteLogEx("error", "update report export job failed: "+err.Error(), map[string]interface{}{
+			"job_id": job.ID.Hex(),
+		})
+	}
+}
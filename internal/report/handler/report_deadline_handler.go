@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportDeadlineHandler struct {
+	service service.ReportDeadlineService
+}
+
+func NewReportDeadlineHandler(s service.ReportDeadlineService) *ReportDeadlineHandler {
+	return &ReportDeadlineHandler{service: s}
+}
+
+func (h *ReportDeadlineHandler) GetDeadlines4Web(c *gin.Context) {
+	var req request.GetReportDeadlinesRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.GetDeadlines4Web(c.Request.Context(), req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report deadlines retrieved successfully", res)
+}
+
+func (h *ReportDeadlineHandler) CreateDeadline4Web(c *gin.Context) {
+	var req request.ReportDeadlineRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.CreateDeadline4Web(c.Request.Context(), req)
+	if err != nil {
+		sendDeadlineError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report deadline created successfully", res)
+}
+
+func (h *ReportDeadlineHandler) UpdateDeadline4Web(c *gin.Context) {
+	var req request.ReportDeadlineRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.UpdateDeadline4Web(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		sendDeadlineError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report deadline updated successfully", res)
+}
+
+func (h *ReportDeadlineHandler) DeleteDeadline4Web(c *gin.Context) {
+	if err := h.service.DeleteDeadline4Web(c.Request.Context(), c.Param("id")); err != nil {
+		sendDeadlineError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report deadline deleted successfully", nil)
+}
+
+func sendDeadlineError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrDeadlineNotFound) {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+		return
+	}
+	helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+}
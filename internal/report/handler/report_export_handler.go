@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/dto/response"
+	"report-service/internal/report/exportjob"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportExportHandler struct {
+	service service.ReportExportService
+}
+
+func NewReportExportHandler(s service.ReportExportService) *ReportExportHandler {
+	return &ReportExportHandler{service: s}
+}
+
+func (h *ReportExportHandler) ExportReportPDF4Web(c *gin.Context) {
+	var req request.ExportReportRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportPDF4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func (h *ReportExportHandler) GetReportCard4Web(c *gin.Context) {
+	var req request.GetReportCardRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.GetReportCard4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report card retrieved successfully", res)
+}
+
+func (h *ReportExportHandler) ExportReportCardHTML4Web(c *gin.Context) {
+	var req request.GetReportCardRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportCardHTML4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func (h *ReportExportHandler) ExportReportDOCX4Web(c *gin.Context) {
+	var req request.ExportReportRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportDOCX4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func (h *ReportExportHandler) ExportReportCardDOCX4Web(c *gin.Context) {
+	var req request.GetReportCardRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportCardDOCX4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func (h *ReportExportHandler) CreateExportJob4Web(c *gin.Context) {
+	var req request.CreateReportExportJobRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.CreateExportJob4Web(c.Request.Context(), req)
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report export job created successfully", res)
+}
+
+func (h *ReportExportHandler) GetExportJob4Web(c *gin.Context) {
+	res, err := h.service.GetExportJob4Web(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report export job retrieved successfully", res)
+}
+
+func (h *ReportExportHandler) DownloadExportJob4Web(c *gin.Context) {
+	job, rc, err := h.service.OpenExportJobArtifact4Web(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		sendExportError(c, err)
+		return
+	}
+	defer rc.Close()
+
+	c.DataFromReader(http.StatusOK, job.ArtifactSize, "application/zip", rc, map[string]string{
+		"Content-Disposition": `attachment; filename="` + job.FileName + `"`,
+	})
+}
+
+func sendExportFile(c *gin.Context, file *response.ExportFile) {
+	disposition := "attachment"
+	if file.Inline {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", disposition+`; filename="`+file.FileName+`"`)
+	c.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+func sendExportError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrExportReportNotFound), errors.Is(err, repository.ErrExportJobNotFound):
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+		return
+	case errors.Is(err, service.ErrExportJobNotReady):
+		helper.SendError(c, http.StatusConflict, err, helper.ErrInvalidOperation)
+		return
+	case errors.Is(err, exportjob.ErrQueueFull):
+		helper.SendError(c, http.StatusServiceUnavailable, err, helper.ErrInternal)
+		return
+	}
+	helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+}
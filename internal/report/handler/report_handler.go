@@ -2,15 +2,24 @@ package handler
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"report-service/helper"
 	"report-service/internal/report/dto/request"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
 	"report-service/internal/report/service"
+	"report-service/internal/report/usecase"
+	"report-service/internal/report/workflow"
+	"report-service/pkg/constants"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+const sseHeartbeatInterval = 25 * time.Second
+
 type ReportHandler struct {
 	service service.ReportService
 }
@@ -26,12 +35,41 @@ func (h *ReportHandler) UploadReport4App(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UploadReport4App(c.Request.Context(), &req); err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	res, err := h.service.UploadReport4App(c.Request.Context(), &req)
+	if err != nil {
+		sendUploadError(c, err)
 		return
 	}
 
-	helper.SendSuccess(c, http.StatusOK, "Report uploaded successfully", nil)
+	helper.SendSuccess(c, http.StatusOK, "Report uploaded successfully", res)
+}
+
+// sendUploadError map lỗi khi lưu report: conflict version, report đang bị lock, sai workflow
+func sendUploadError(c *gin.Context, err error) {
+	var conflict *repository.VersionConflictError
+	if errors.As(err, &conflict) {
+		helper.SendErrorWithData(c, http.StatusConflict, err, helper.ErrConflict, gin.H{"current_version": conflict.CurrentVersion, "conflicts": conflict.Conflicts})
+		return
+	}
+	var locked *repository.LockHeldError
+	if errors.As(err, &locked) {
+		helper.SendErrorWithData(c, http.StatusLocked, err, helper.ErrConflict, gin.H{"editing_lock": locked.Lock})
+		return
+	}
+	var invalid *schema.ValidationError
+	if errors.As(err, &invalid) {
+		helper.SendErrorWithData(c, http.StatusBadRequest, err, helper.ErrInvalidRequest, gin.H{"errors": invalid.Errors})
+		return
+	}
+	if errors.Is(err, usecase.ErrNoOrganization) {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+	if errors.Is(err, workflow.ErrInvalidTransition) || errors.Is(err, workflow.ErrUnknownStatus) || errors.Is(err, repository.ErrUnknownSection) {
+		helper.SendError(c, http.StatusUnprocessableEntity, err, helper.ErrInvalidOperation)
+		return
+	}
+	helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
 }
 
 func (h *ReportHandler) GetReport4App(c *gin.Context) {
@@ -92,12 +130,13 @@ func (h *ReportHandler) UploadReport4Web(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.UploadReport4Web(c.Request.Context(), &req); err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	res, err := h.service.UploadReport4Web(c.Request.Context(), &req)
+	if err != nil {
+		sendUploadError(c, err)
 		return
 	}
 
-	helper.SendSuccess(c, http.StatusOK, "Report uploaded successfully", nil)
+	helper.SendSuccess(c, http.StatusOK, "Report uploaded successfully", res)
 }
 
 func (h *ReportHandler) UploadClassroomReport4Web(c *gin.Context) {
@@ -108,7 +147,7 @@ func (h *ReportHandler) UploadClassroomReport4Web(c *gin.Context) {
 	}
 
 	if err := h.service.UploadClassroomReport4Web(c.Request.Context(), req); err != nil {
-		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		sendUploadError(c, err)
 		return
 	}
 
@@ -165,6 +204,42 @@ func (h *ReportHandler) ApplyTopicPlanTemplateIsClassroom2Report(c *gin.Context)
 	helper.SendSuccess(c, http.StatusOK, "Report template applied successfully", nil)
 }
 
+func (h *ReportHandler) GetReviewQueue4Web(c *gin.Context) {
+	var req request.GetReviewQueueRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.GetReviewQueue4Web(c.Request.Context(), req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Review queue retrieved successfully", res)
+}
+
+func (h *ReportHandler) GetReviewSLA4Web(c *gin.Context) {
+	var req request.GetReviewSLARequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.GetReviewSLA4Web(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, usecase.ErrNoOrganization) {
+			helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Review SLA retrieved successfully", res)
+}
+
 func (h *ReportHandler) GetReportOverViewAllClassroom4Web(c *gin.Context) {
 	termID := c.Query("term_id")
 	if termID == "" {
@@ -216,3 +291,248 @@ func (h *ReportHandler) GetReportOverViewByClassroom4Web(c *gin.Context) {
 
 	helper.SendSuccess(c, http.StatusOK, "Report retrieved successfully", reports)
 }
+
+func (h *ReportHandler) ExportReportOverViewAllClassroom4Web(c *gin.Context) {
+	var req request.ExportReportOverViewAllClassroomRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportOverViewAllClassroom4Web(c.Request.Context(), req)
+	if err != nil {
+		sendOverviewExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func (h *ReportHandler) ExportReportOverViewByClassroom4Web(c *gin.Context) {
+	var req request.ExportReportOverViewByClassroomRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	file, err := h.service.ExportReportOverViewByClassroom4Web(c.Request.Context(), req)
+	if err != nil {
+		sendOverviewExportError(c, err)
+		return
+	}
+	sendExportFile(c, file)
+}
+
+func sendOverviewExportError(c *gin.Context, err error) {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+		return
+	}
+	helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+}
+
+func (h *ReportHandler) SendBackReport4Web(c *gin.Context) {
+	var req request.SendBackReportRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.SendBackReport4Web(c.Request.Context(), req); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+			return
+		}
+		sendUploadError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Report sent back to teacher successfully", nil)
+}
+
+func (h *ReportHandler) AcceptClassroomReports4Web(c *gin.Context) {
+	var req request.AcceptClassroomReportRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.AcceptClassroomReports4Web(c.Request.Context(), req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Classroom reports accepted successfully", res)
+}
+
+func (h *ReportHandler) RestoreReportFromHistory4Web(c *gin.Context) {
+	historyID := c.Param("history_id")
+	if historyID == "" {
+		helper.SendError(c, http.StatusBadRequest, errors.New("historyID is required"), helper.ErrInvalidRequest)
+		return
+	}
+
+	var req request.RestoreReportRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.service.RestoreReportFromHistory4Web(c.Request.Context(), historyID, req); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+			return
+		}
+		sendUploadError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Report restored successfully", nil)
+}
+
+func (h *ReportHandler) AcquireEditingLock4App(c *gin.Context) {
+	h.updateEditingLock4App(c, constants.EditingLockAcquire)
+}
+
+func (h *ReportHandler) RenewEditingLock4App(c *gin.Context) {
+	h.updateEditingLock4App(c, constants.EditingLockRenew)
+}
+
+func (h *ReportHandler) ReleaseEditingLock4App(c *gin.Context) {
+	h.updateEditingLock4App(c, constants.EditingLockRelease)
+}
+
+func (h *ReportHandler) AcquireEditingLock4Web(c *gin.Context) {
+	h.updateEditingLock4Web(c, constants.EditingLockAcquire)
+}
+
+func (h *ReportHandler) RenewEditingLock4Web(c *gin.Context) {
+	h.updateEditingLock4Web(c, constants.EditingLockRenew)
+}
+
+func (h *ReportHandler) ReleaseEditingLock4Web(c *gin.Context) {
+	h.updateEditingLock4Web(c, constants.EditingLockRelease)
+}
+
+func (h *ReportHandler) updateEditingLock4App(c *gin.Context, action constants.EditingLockAction) {
+	var req request.EditingLockRequest4App
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	lock, err := h.service.UpdateEditingLock4App(c.Request.Context(), req, action)
+	if err != nil {
+		sendEditingLockError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Editing lock updated successfully", lock)
+}
+
+func (h *ReportHandler) updateEditingLock4Web(c *gin.Context, action constants.EditingLockAction) {
+	var req request.EditingLockRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	lock, err := h.service.UpdateEditingLock4Web(c.Request.Context(), req, action)
+	if err != nil {
+		sendEditingLockError(c, err)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Editing lock updated successfully", lock)
+}
+
+func sendEditingLockError(c *gin.Context, err error) {
+	var locked *repository.LockHeldError
+	switch {
+	case errors.As(err, &locked):
+		helper.SendErrorWithData(c, http.StatusLocked, err, helper.ErrConflict, gin.H{"editing_lock": locked.Lock})
+	case errors.Is(err, repository.ErrLockNotHeld):
+		helper.SendError(c, http.StatusConflict, err, helper.ErrConflict)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+	default:
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+	}
+}
+
+// StreamClassroomEvents4Web giữ kết nối SSE và đẩy event khi report trong classroom được lưu, đổi status hoặc đổi lock
+func (h *ReportHandler) StreamClassroomEvents4Web(c *gin.Context) {
+	var req request.ClassroomReportEventRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	sub, err := h.service.SubscribeClassroomEvents4Web(c.Request.Context(), req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// ping định kỳ để proxy không cắt kết nối idle
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(e.Type), e)
+			return true
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func (h *ReportHandler) GetSyncChanges4App(c *gin.Context) {
+	var req request.SyncChangesRequest4App
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.GetSyncChanges4App(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Report changes retrieved successfully", res)
+}
+
+func (h *ReportHandler) PushSync4App(c *gin.Context) {
+	var req request.SyncPushRequest4App
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.PushSync4App(c.Request.Context(), req)
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+
+	helper.SendSuccess(c, http.StatusOK, "Reports synced", res)
+}
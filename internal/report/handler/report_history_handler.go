@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/repository"
 	"report-service/internal/report/service"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ReportHistoryHandler struct {
@@ -24,3 +29,45 @@ func (h *ReportHistoryHandler) GetByEditor4App(c *gin.Context) {
 	}
 	helper.SendSuccess(c, http.StatusOK, "History retrieved successfully", histories)
 }
+
+func (h *ReportHistoryHandler) GetDiffsByReport4Web(c *gin.Context) {
+	reportID := c.Param("report_id")
+	if reportID == "" {
+		helper.SendError(c, http.StatusBadRequest, errors.New("reportID is required"), helper.ErrInvalidRequest)
+		return
+	}
+	if !primitive.IsValidObjectID(reportID) {
+		helper.SendError(c, http.StatusBadRequest, errors.New("invalid reportID"), helper.ErrInvalidRequest)
+		return
+	}
+
+	diffs, err := h.service.GetDiffsByReport4Web(c.Request.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			helper.SendError(c, http.StatusNotFound, errors.New("report not found"), helper.ErrNotFound)
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "History diffs retrieved successfully", diffs)
+}
+
+func (h *ReportHistoryHandler) Search4Web(c *gin.Context) {
+	var req request.SearchReportHistoryRequest4Web
+	if err := c.ShouldBindQuery(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.Search4Web(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCursor) {
+			helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "History retrieved successfully", res)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportSchemaHandler struct {
+	service service.ReportSchemaService
+}
+
+func NewReportSchemaHandler(s service.ReportSchemaService) *ReportSchemaHandler {
+	return &ReportSchemaHandler{service: s}
+}
+
+func (h *ReportSchemaHandler) GetReportSchema4Web(c *gin.Context) {
+	res, err := h.service.GetReportSchema4Web(c.Request.Context())
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report schema retrieved successfully", res)
+}
+
+func (h *ReportSchemaHandler) UpdateReportSchema4Web(c *gin.Context) {
+	var req request.UpdateReportSchemaRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.UpdateReportSchema4Web(c.Request.Context(), req)
+	if err != nil {
+		var invalid *schema.ValidationError
+		if errors.As(err, &invalid) {
+			helper.SendErrorWithData(c, http.StatusBadRequest, err, helper.ErrInvalidRequest, gin.H{"errors": invalid.Errors})
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report schema updated successfully", res)
+}
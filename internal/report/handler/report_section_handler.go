@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportSectionHandler struct {
+	service service.ReportSectionService
+}
+
+func NewReportSectionHandler(s service.ReportSectionService) *ReportSectionHandler {
+	return &ReportSectionHandler{service: s}
+}
+
+func (h *ReportSectionHandler) GetSections4Web(c *gin.Context) {
+	res, err := h.service.GetSections4Web(c.Request.Context())
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report sections retrieved successfully", res)
+}
+
+func (h *ReportSectionHandler) CreateSection4Web(c *gin.Context) {
+	var req request.CreateReportSectionRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.CreateSection4Web(c.Request.Context(), req)
+	if err != nil {
+		sendSectionError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report section created successfully", res)
+}
+
+func (h *ReportSectionHandler) UpdateSection4Web(c *gin.Context) {
+	var req request.UpdateReportSectionRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.UpdateSection4Web(c.Request.Context(), c.Param("id"), req)
+	if err != nil {
+		sendSectionError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report section updated successfully", res)
+}
+
+func (h *ReportSectionHandler) DeleteSection4Web(c *gin.Context) {
+	if err := h.service.DeleteSection4Web(c.Request.Context(), c.Param("id")); err != nil {
+		sendSectionError(c, err)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report section deleted successfully", nil)
+}
+
+func sendSectionError(c *gin.Context, err error) {
+	var invalid *schema.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		helper.SendErrorWithData(c, http.StatusBadRequest, err, helper.ErrInvalidRequest, gin.H{"errors": invalid.Errors})
+	case errors.Is(err, repository.ErrSectionNotFound):
+		helper.SendError(c, http.StatusNotFound, err, helper.ErrNotFound)
+	case errors.Is(err, repository.ErrSectionKeyExists):
+		helper.SendError(c, http.StatusConflict, err, helper.ErrConflict)
+	default:
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/service"
+	"report-service/internal/report/workflow"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportStatusModelHandler struct {
+	service service.ReportStatusModelService
+}
+
+func NewReportStatusModelHandler(s service.ReportStatusModelService) *ReportStatusModelHandler {
+	return &ReportStatusModelHandler{service: s}
+}
+
+func (h *ReportStatusModelHandler) GetStatusModel4Web(c *gin.Context) {
+	res, err := h.service.GetStatusModel4Web(c.Request.Context())
+	if err != nil {
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInternal)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report statuses retrieved successfully", res)
+}
+
+func (h *ReportStatusModelHandler) UpdateStatusModel4Web(c *gin.Context) {
+	var req request.UpdateReportStatusModelRequest4Web
+	if err := c.ShouldBindJSON(&req); err != nil {
+		helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+		return
+	}
+
+	res, err := h.service.UpdateStatusModel4Web(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, workflow.ErrInvalidStatusModel) {
+			helper.SendError(c, http.StatusBadRequest, err, helper.ErrInvalidRequest)
+			return
+		}
+		helper.SendError(c, http.StatusInternalServerError, err, helper.ErrInvalidOperation)
+		return
+	}
+	helper.SendSuccess(c, http.StatusOK, "Report statuses updated successfully", res)
+}
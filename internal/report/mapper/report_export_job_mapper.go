@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"report-service/internal/report/dto/response"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+)
+
+func MapReportExportJob(job *model.ReportExportJob) response.ReportExportJob4Web {
+	res := response.ReportExportJob4Web{
+		ID:           job.ID.Hex(),
+		ClassroomID:  job.ClassroomID,
+		TermID:       job.TermID,
+		TopicID:      job.TopicID,
+		Language:     job.Language,
+		Format:       job.Format,
+		Status:       job.Status,
+		Total:        job.Total,
+		Processed:    job.Processed,
+		Failed:       job.Failed,
+		Error:        job.Error,
+		FileName:     job.FileName,
+		ArtifactSize: job.ArtifactSize,
+		CreatedAt:    job.CreatedAt,
+		StartedAt:    job.StartedAt,
+		FinishedAt:   job.FinishedAt,
+	}
+
+	switch {
+	case job.Status == string(constants.ReportExportJobCompleted):
+		res.Progress = 100
+	case job.Total > 0:
+		res.Progress = float32(job.Processed) * 100 / float32(job.Total)
+	}
+	return res
+}
@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"report-service/internal/report/diff"
 	"report-service/internal/report/dto/response"
 	"report-service/internal/report/model"
 )
@@ -13,11 +14,16 @@ func MapReportHistoryToRes4App(history *model.ReportHistory) response.ReportHist
 	}
 
 	return response.ReportHistoryResponse{
-		ID:        history.ID.Hex(),
-		ReportID:  history.ReportID.Hex(),
-		EditorID:  history.EditorID,
-		Report:    reportRes,
-		Timestamp: history.Timestamp,
+		ID:          history.ID.Hex(),
+		ReportID:    history.ReportID.Hex(),
+		StudentID:   history.StudentID,
+		ClassroomID: history.ClassroomID,
+		Type:        history.Type,
+		EditorID:    history.EditorID,
+		EditorRole:  history.EditorRole,
+		Report:      reportRes,
+		Transitions: history.Transitions,
+		Timestamp:   history.Timestamp,
 	}
 }
 
@@ -29,3 +35,38 @@ func MapReportHistoryListToRes4App(histories []*model.ReportHistory) []response.
 	}
 	return result
 }
+
+// MapReportHistoryListToDiffRes so sánh từng history với history liền trước (histories đã sắp xếp theo thời gian)
+func MapReportHistoryListToDiffRes(histories []*model.ReportHistory) []response.ReportHistoryDiffResponse {
+	result := make([]response.ReportHistoryDiffResponse, 0, len(histories))
+
+	var previous *model.Report
+	for _, h := range histories {
+		changes := diff.Reports(previous, h.Report)
+		if changes == nil {
+			changes = []diff.FieldChange{}
+		}
+
+		sourceHistoryID := ""
+		if h.SourceHistoryID != nil {
+			sourceHistoryID = h.SourceHistoryID.Hex()
+		}
+
+		result = append(result, response.ReportHistoryDiffResponse{
+			ID:              h.ID.Hex(),
+			ReportID:        h.ReportID.Hex(),
+			Type:            h.Type,
+			EditorID:        h.EditorID,
+			EditorRole:      h.EditorRole,
+			SourceHistoryID: sourceHistoryID,
+			Timestamp:       h.Timestamp,
+			Changes:         changes,
+		})
+
+		if h.Report != nil {
+			previous = h.Report
+		}
+	}
+
+	return result
+}
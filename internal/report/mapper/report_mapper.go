@@ -7,6 +7,10 @@ import (
 	gw_response "report-service/internal/gateway/dto/response"
 	"report-service/internal/report/dto/response"
 	"report-service/internal/report/model"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/workflow"
+	"report-service/pkg/constants"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -110,9 +114,10 @@ func MapReportToResDTO(
 		teacherEditor = *teacher
 	}
 
-	editing := true
-	if report.Editing != nil {
-		editing = *report.Editing
+	// editing: report đang có người giữ lock chỉnh sửa
+	var editingLock *model.EditingLock
+	if report.Lock.Active(time.Now()) {
+		editingLock = report.Lock
 	}
 
 	// --- Thêm latest_update_time cho từng section ---
@@ -137,8 +142,11 @@ func MapReportToResDTO(
 		TermID:                     report.TermID,
 		Language:                   report.Language,
 		Status:                     report.Status,
-		Editing:                    editing,
+		Editing:                    editingLock != nil,
+		EditingLock:                editingLock,
 		ReportData:                 report.ReportData,
+		Rejections:                 report.Rejections,
+		Version:                    report.Version,
 		CreatedAt:                  report.CreatedAt,
 		Editor:                     teacherEditor,
 		ManagerCommentPreviousTerm: managerCmPrevious,
@@ -147,6 +155,55 @@ func MapReportToResDTO(
 	}
 }
 
+// MapCustomSections bổ sung section tuỳ biến của organization vào report_data:
+// section chưa có dữ liệu thì tạo rỗng, đã có thì thêm key còn thiếu + latest_update_time
+func MapCustomSections(res *response.ReportResponse, defs []*model.ReportSectionDefinition) {
+	if res.ID == "" || len(defs) == 0 {
+		return
+	}
+	if res.ReportData == nil {
+		res.ReportData = bson.M{}
+	}
+
+	for _, def := range defs {
+		sectionData, ok := res.ReportData[def.Key].(bson.M)
+		if !ok {
+			sectionData = bson.M{
+				"status":         string(constants.SectionStatusEmpty),
+				"color":          "",
+				"teacher_report": "",
+				"updated_at":     "",
+			}
+		}
+		for _, k := range []string{"manager_note", "manager_comment", "manager_updated_at"} {
+			if _, ok := sectionData[k]; !ok {
+				sectionData[k] = ""
+			}
+		}
+
+		switch constants.ReportSectionType(def.Type) {
+		case constants.ReportSectionTypeRating:
+			if _, ok := sectionData["rating"]; !ok {
+				sectionData["rating"] = nil
+			}
+		case constants.ReportSectionTypeChecklist:
+			for _, item := range def.Items {
+				if _, ok := sectionData[item.Key]; !ok {
+					sectionData[item.Key] = false
+				}
+			}
+		}
+
+		updatedAt, _ := sectionData["updated_at"].(string)
+		managerUpdatedAt, _ := sectionData["manager_updated_at"].(string)
+		sectionData["latest_update_time"] = helper.GetLatestTimeStr(updatedAt, managerUpdatedAt)
+
+		res.ReportData[def.Key] = sectionData
+	}
+
+	res.CustomSections = defs
+}
+
 // MapReportListToResDTO maps slice of model.Report to slice of ReportResponse
 func MapReportListToResDTO(reports []*model.Report) []response.ReportResponse {
 	result := make([]response.ReportResponse, 0, len(reports))
@@ -188,19 +245,11 @@ func MapReportToStruct(report *model.Report) (*model.Reportstruct, error) {
 	return res, nil
 }
 
-func MapReportsToStruct(reports []*model.Report) ([]*model.Reportstruct, error) {
+func MapReportsToStruct(reports []*model.Report, customSections []*model.ReportSectionDefinition, progress *workflow.Progress) ([]*model.Reportstruct, error) {
 	if len(reports) == 0 {
 		return nil, nil
 	}
 
-	statusMap := map[string]int{
-		"Empty":    0,
-		"Teacher":  10,
-		"Manager":  15,
-		"Done":     20,
-		"Approved": 25,
-	}
-
 	var result []*model.Reportstruct
 
 	for _, report := range reports {
@@ -218,11 +267,8 @@ func MapReportsToStruct(reports []*model.Report) ([]*model.Reportstruct, error)
 			return nil, fmt.Errorf("failed to unmarshal to model.ReportData: %w", err)
 		}
 
-		// Tính progress
-		progress := 0
-		progress += statusMap[rd.Before.Status]
-		progress += statusMap[rd.Now.Status]
-		progress += statusMap[rd.Conclusion.Status]
+		// Tính progress theo bộ status của organization, gồm cả section tuỳ biến
+		_, sectionsProgress := schema.SectionProgress(report.ReportData, customSections, progress)
 
 		res := &model.Reportstruct{
 			ID:         report.ID.Hex(),
@@ -234,7 +280,7 @@ func MapReportsToStruct(reports []*model.Report) ([]*model.Reportstruct, error)
 			Status:     report.Status,
 			ReportData: rd,
 			CreatedAt:  report.CreatedAt,
-			Progress:   progress,
+			Progress:   int(sectionsProgress),
 		}
 
 		result = append(result, res)
@@ -256,8 +302,33 @@ func MapReport2Print(report *model.Report) *response.GetReport2Print {
 	}
 
 	return &response.GetReport2Print{
+		Language:   report.Language,
 		Before:     getContent("before"),
 		Now:        getContent("now"),
 		Conclusion: getContent("conclusion"),
 	}
 }
+
+// MapReportComments lấy manager_comment của before/now/conclusion, nil nếu chưa có nhận xét nào
+func MapReportComments(report *model.Report) *response.SectionComments {
+	reportData := helper.ToBsonM(report.ReportData)
+
+	getComment := func(section string) string {
+		if sec, ok := reportData[section].(bson.M); ok {
+			if comment, ok := sec["manager_comment"].(string); ok {
+				return comment
+			}
+		}
+		return ""
+	}
+
+	comments := &response.SectionComments{
+		Before:     getComment("before"),
+		Now:        getComment("now"),
+		Conclusion: getComment("conclusion"),
+	}
+	if comments.Before == "" && comments.Now == "" && comments.Conclusion == "" {
+		return nil
+	}
+	return comments
+}
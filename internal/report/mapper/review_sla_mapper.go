@@ -0,0 +1,21 @@
+package mapper
+
+import (
+	"math"
+	"report-service/internal/report/analytics"
+	"report-service/internal/report/dto/response"
+	"time"
+)
+
+func MapDurationStats(s analytics.Stats) response.DurationStats4Web {
+	return response.DurationStats4Web{
+		Count:          s.Count,
+		AverageMinutes: roundMinutes(s.Average),
+		MedianMinutes:  roundMinutes(s.Median),
+		P90Minutes:     roundMinutes(s.P90),
+	}
+}
+
+func roundMinutes(d time.Duration) float64 {
+	return math.Round(d.Minutes()*100) / 100
+}
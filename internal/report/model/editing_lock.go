@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// EditingLock: lease chỉnh sửa report, tự hết hạn sau ExpiresAt nếu holder không renew
+type EditingLock struct {
+	HolderID   string    `bson:"holder_id" json:"holder_id"`
+	HolderName string    `bson:"holder_name" json:"holder_name"`
+	Client     string    `bson:"client" json:"client"`
+	AcquiredAt time.Time `bson:"acquired_at" json:"acquired_at"`
+	ExpiresAt  time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// Active: lock còn hiệu lực tại thời điểm now
+func (l *EditingLock) Active(now time.Time) bool {
+	return l != nil && now.Before(l.ExpiresAt)
+}
+
+// HeldBy: lock thuộc về holder trên đúng client (app/web)
+func (l *EditingLock) HeldBy(holderID, client string) bool {
+	return l != nil && l.HolderID == holderID && l.Client == client
+}
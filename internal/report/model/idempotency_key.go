@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyKey lưu kết quả của một request ghi theo Idempotency-Key để replay khi client gửi lại
+type IdempotencyKey struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       string             `bson:"user_id"`
+	Key          string             `bson:"key"`
+	RequestHash  string             `bson:"request_hash"`
+	Method       string             `bson:"method"`
+	Path         string             `bson:"path"`
+	Status       string             `bson:"status"`
+	ResponseCode int                `bson:"response_code,omitempty"`
+	ResponseBody []byte             `bson:"response_body,omitempty"`
+	ContentType  string             `bson:"content_type,omitempty"`
+	CreatedAt    time.Time          `bson:"created_at"`
+	ExpiresAt    time.Time          `bson:"expires_at"`
+}
@@ -15,10 +15,32 @@ type Report struct {
 	TermID     string             `bson:"term_id"`
 	Language   string             `bson:"language"`
 	Status     string             `bson:"status"`
-	Editing    *bool              `bson:"editing" json:"editing"`
 	ReportData bson.M             `bson:"report_data" json:"report_data"`
+	Rejections []SectionRejection `bson:"rejections,omitempty" json:"rejections,omitempty"`
+	Lock       *EditingLock       `bson:"editing_lock,omitempty" json:"editing_lock,omitempty"`
+	Version    int64              `bson:"version" json:"version"`
+	ChangeSeq  int64              `bson:"change_seq,omitempty" json:"-"`
 	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+
+	// OrganizationID: organization của người ghi gần nhất, để job nền lọc theo organization
+	OrganizationID string `bson:"organization_id,omitempty" json:"organization_id,omitempty"`
+
+	// ExpectedVersion là version client đang giữ khi gửi lên, nil = không kiểm tra
+	ExpectedVersion *int64 `bson:"-" json:"-"`
+	// Writer là người đang ghi, dùng để kiểm tra editing lock, nil = không kiểm tra
+	Writer *EditingLock `bson:"-" json:"-"`
+	// Saved là document ngay sau lần ghi này (đọc cùng lúc ghi), history lưu đúng version/snapshot này
+	Saved *Report `bson:"-" json:"-"`
+}
+
+// SectionRejection: manager trả section về cho teacher kèm lý do
+type SectionRejection struct {
+	Section    string     `bson:"section" json:"section"`
+	Reason     string     `bson:"reason" json:"reason"`
+	RejectedBy string     `bson:"rejected_by" json:"rejected_by"`
+	RejectedAt time.Time  `bson:"rejected_at" json:"rejected_at"`
+	ResolvedAt *time.Time `bson:"resolved_at" json:"resolved_at"`
 }
 
 type ReportData struct {
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportDeadline: hạn nộp report của organization theo term,
+// có thể thu hẹp theo topic và/hoặc section (rỗng = áp dụng cho tất cả)
+type ReportDeadline struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID string             `bson:"organization_id" json:"organization_id"`
+	TermID         string             `bson:"term_id" json:"term_id"`
+	TopicID        string             `bson:"topic_id,omitempty" json:"topic_id,omitempty"`
+	Section        string             `bson:"section,omitempty" json:"section,omitempty"`
+	DueAt          time.Time          `bson:"due_at" json:"due_at"`
+	CreatedBy      string             `bson:"created_by" json:"created_by"`
+	UpdatedBy      string             `bson:"updated_by" json:"updated_by"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Specificity: deadline càng cụ thể càng được ưu tiên (topic + section > topic > section > chỉ term)
+func (d *ReportDeadline) Specificity() int {
+	score := 0
+	if d.TopicID != "" {
+		score += 2
+	}
+	if d.Section != "" {
+		score++
+	}
+	return score
+}
+
+// Matches kiểm tra deadline có áp dụng cho topic/section này không
+func (d *ReportDeadline) Matches(topicID, section string) bool {
+	return (d.TopicID == "" || d.TopicID == topicID) && (d.Section == "" || d.Section == section)
+}
+
+// ResolveDeadline chọn deadline cụ thể nhất áp dụng cho topic/section, cùng mức thì lấy hạn sớm hơn
+func ResolveDeadline(deadlines []*ReportDeadline, topicID, section string) *ReportDeadline {
+	var best *ReportDeadline
+	for _, d := range deadlines {
+		if !d.Matches(topicID, section) {
+			continue
+		}
+		if best == nil || d.Specificity() > best.Specificity() ||
+			(d.Specificity() == best.Specificity() && d.DueAt.Before(best.DueAt)) {
+			best = d
+		}
+	}
+	return best
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportExportJob: job xuất report của cả lớp thành file ZIP, chạy nền
+type ReportExportJob struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID string             `bson:"organization_id" json:"organization_id"`
+	ClassroomID    string             `bson:"classroom_id" json:"classroom_id"`
+	TermID         string             `bson:"term_id" json:"term_id"`
+	TopicID        string             `bson:"topic_id,omitempty" json:"topic_id,omitempty"` // rỗng = mọi topic
+	Language       string             `bson:"language" json:"language"`
+	Format         string             `bson:"format" json:"format"`
+	Status         string             `bson:"status" json:"status"`
+	Total          int                `bson:"total" json:"total"`
+	Processed      int                `bson:"processed" json:"processed"`
+	Failed         int                `bson:"failed" json:"failed"`
+	Error          string             `bson:"error,omitempty" json:"error,omitempty"`
+	ArtifactKey    string             `bson:"artifact_key,omitempty" json:"-"`
+	ArtifactSize   int64              `bson:"artifact_size,omitempty" json:"artifact_size,omitempty"`
+	FileName       string             `bson:"file_name,omitempty" json:"file_name,omitempty"`
+	CreatedBy      string             `bson:"created_by" json:"created_by"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	StartedAt      *time.Time         `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt     *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
@@ -3,16 +3,47 @@ package model
 import (
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportHistory struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty"`
-	ReportID    primitive.ObjectID `bson:"report_id"`
-	ClassroomID string             `bson:"classroom_id"`
-	Type        string             `bson:"type"`
-	EditorID    string             `bson:"editor_id"`
-	EditorRole  string             `bson:"editor_role"`
-	Report      *Report            `bson:"report"`
-	Timestamp   time.Time          `bson:"timestamp"`
+	ID              primitive.ObjectID  `bson:"_id,omitempty"`
+	ReportID        primitive.ObjectID  `bson:"report_id"`
+	ClassroomID     string              `bson:"classroom_id"`
+	OrganizationID  string              `bson:"organization_id,omitempty"`
+	StudentID       string              `bson:"student_id,omitempty"`
+	Type            string              `bson:"type"`
+	EditorID        string              `bson:"editor_id"`
+	EditorRole      string              `bson:"editor_role"`
+	Report          *Report             `bson:"report,omitempty"`
+	Version         int64               `bson:"version,omitempty"`
+	Mode            string              `bson:"mode,omitempty"`
+	Seq             int64               `bson:"seq"`
+	Delta           *ReportDelta        `bson:"delta,omitempty"`
+	Transitions     []SectionTransition `bson:"transitions,omitempty"`
+	SourceHistoryID *primitive.ObjectID `bson:"source_history_id,omitempty"`
+	Timestamp       time.Time           `bson:"timestamp"`
+}
+
+type SectionTransition struct {
+	Section string `bson:"section" json:"section"`
+	From    string `bson:"from" json:"from"`
+	To      string `bson:"to" json:"to"`
+	Role    string `bson:"role" json:"role"`
+}
+
+// ReportDelta chỉ lưu phần thay đổi so với version liền trước
+type ReportDelta struct {
+	Set     bson.M       `bson:"set,omitempty"`
+	Unset   []string     `bson:"unset,omitempty"`
+	Changes []FieldDelta `bson:"changes,omitempty"`
+}
+
+// FieldDelta: thay đổi ở report_data.<section>.<field>, Field rỗng là thay cả section
+type FieldDelta struct {
+	Section string      `bson:"section"`
+	Field   string      `bson:"field,omitempty"`
+	Value   interface{} `bson:"value"`
+	Unset   bool        `bson:"unset,omitempty"`
 }
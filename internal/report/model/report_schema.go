@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FieldSchema mô tả một field trong section của report_data
+type FieldSchema struct {
+	Type      string `bson:"type" json:"type"`
+	MaxLength int    `bson:"max_length,omitempty" json:"max_length,omitempty"`
+
+	// giới hạn cho field number (vd thang điểm rating)
+	Min *float64 `bson:"min,omitempty" json:"min,omitempty"`
+	Max *float64 `bson:"max,omitempty" json:"max,omitempty"`
+}
+
+type SectionSchema struct {
+	Fields map[string]FieldSchema `bson:"fields" json:"fields"`
+}
+
+// ReportSchema: các section/field được phép trong report_data.
+// Lưu trong DB là phần mở rộng của từng organization, gộp với schema mặc định khi validate.
+type ReportSchema struct {
+	ID             primitive.ObjectID       `bson:"_id,omitempty" json:"-"`
+	OrganizationID string                   `bson:"organization_id" json:"organization_id,omitempty"`
+	Sections       map[string]SectionSchema `bson:"sections" json:"sections"`
+	UpdatedBy      string                   `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt      time.Time                `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportSectionDefinition: section tuỳ biến của organization (vd "social_skills"),
+// lưu trong report_data theo Key, đi qua cùng workflow status như before/now/conclusion
+type ReportSectionDefinition struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	OrganizationID string             `bson:"organization_id" json:"organization_id"`
+	Key            string             `bson:"key" json:"key"`
+	Title          string             `bson:"title" json:"title"`
+	Type           string             `bson:"type" json:"type"`
+	Rating         *RatingScale       `bson:"rating,omitempty" json:"rating,omitempty"`
+	Items          []ChecklistItem    `bson:"items,omitempty" json:"items,omitempty"`
+	Order          int                `bson:"order" json:"order"`
+	TrackProgress  bool               `bson:"track_progress" json:"track_progress"`
+	CreatedBy      string             `bson:"created_by" json:"created_by"`
+	UpdatedBy      string             `bson:"updated_by" json:"updated_by"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	// RetiredAt: section đã bị xoá, không hiển thị/tính progress nữa nhưng report cũ vẫn lưu lại được dữ liệu của nó
+	RetiredAt *time.Time `bson:"retired_at,omitempty" json:"retired_at,omitempty"`
+}
+
+// RatingScale: thang điểm cho section kiểu rating
+type RatingScale struct {
+	Min int `bson:"min" json:"min"`
+	Max int `bson:"max" json:"max"`
+}
+
+// ChecklistItem: một mục của section kiểu checklist, lưu thành field boolean theo Key
+type ChecklistItem struct {
+	Key   string `bson:"key" json:"key"`
+	Label string `bson:"label" json:"label"`
+}
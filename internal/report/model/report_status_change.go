@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReportStatusChange: một lần section đổi status, ghi kèm mỗi history có transition
+type ReportStatusChange struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID       primitive.ObjectID `bson:"report_id" json:"report_id"`
+	OrganizationID string             `bson:"organization_id,omitempty" json:"organization_id,omitempty"`
+	TermID         string             `bson:"term_id" json:"term_id"`
+	TopicID        string             `bson:"topic_id" json:"topic_id"`
+	StudentID      string             `bson:"student_id" json:"student_id"`
+	EditorID       string             `bson:"editor_id" json:"editor_id"`
+	Language       string             `bson:"language" json:"language"`
+	Section        string             `bson:"section" json:"section"`
+	From           string             `bson:"from" json:"from"`
+	To             string             `bson:"to" json:"to"`
+	Role           string             `bson:"role" json:"role"`
+	ChangedBy      string             `bson:"changed_by" json:"changed_by"`
+	ChangedAt      time.Time          `bson:"changed_at" json:"changed_at"`
+}
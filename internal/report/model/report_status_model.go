@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatusDefinition: cách organization gọi tên, hiển thị và tính điểm một status của section.
+// Status là status của workflow, Name là tên organization dùng khi gửi dữ liệu (rỗng = dùng Status).
+type StatusDefinition struct {
+	Status string  `bson:"status" json:"status"`
+	Name   string  `bson:"name,omitempty" json:"name,omitempty"`
+	Label  string  `bson:"label" json:"label"`
+	Order  int     `bson:"order" json:"order"`
+	Weight float32 `bson:"weight" json:"weight"`
+}
+
+// ReportStatusModel: bộ status của organization, dùng chung cho mọi phép tính progress
+type ReportStatusModel struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	OrganizationID string             `bson:"organization_id" json:"organization_id,omitempty"`
+	Statuses       []StatusDefinition `bson:"statuses" json:"statuses"`
+	UpdatedBy      string             `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,87 @@
+package reminder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"report-service/logger"
+	"time"
+)
+
+// Item: một section của report chưa nộp, đã quá hạn hoặc sắp đến hạn
+type Item struct {
+	ReportID  string    `json:"report_id"`
+	StudentID string    `json:"student_id"`
+	TopicID   string    `json:"topic_id"`
+	TermID    string    `json:"term_id"`
+	Language  string    `json:"language"`
+	Section   string    `json:"section"`
+	Status    string    `json:"status"`
+	DueAt     time.Time `json:"due_at"`
+	Overdue   bool      `json:"overdue"`
+}
+
+// Digest gom mọi section cần nhắc của một teacher vào một thông báo
+type Digest struct {
+	TeacherID      string    `json:"teacher_id"`
+	OrganizationID string    `json:"organization_id"`
+	GeneratedAt    time.Time `json:"generated_at"`
+	Overdue        int       `json:"overdue"`
+	DueSoon        int       `json:"due_soon"`
+	Items          []Item    `json:"items"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, digest Digest) error
+}
+
+// LogNotifier ghi digest ra log, dùng khi chưa cấu hình kênh gửi thật
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, digest Digest) error {
+	logger.WriteLogEx("info", "report reminder", digest)
+	return nil
+}
+
+// WebhookNotifier POST digest dạng JSON tới URL cấu hình
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{url: url, client: client}
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, digest Digest) error {
+	body, err := json.Marshal(digest)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send reminder webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("send reminder webhook failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
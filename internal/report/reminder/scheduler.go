@@ -0,0 +1,262 @@
+package reminder
+
+import (
+	"context"
+	"report-service/helper"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/workflow"
+	"report-service/logger"
+	"report-service/pkg/constants"
+	"sort"
+	"time"
+)
+
+type Config struct {
+	Interval      time.Duration // chu kỳ quét
+	Lookahead     time.Duration // nhắc trước hạn bao lâu
+	OverdueWindow time.Duration // quá hạn lâu hơn mức này thì thôi nhắc (term cũ)
+	Cooldown      time.Duration // khoảng cách tối thiểu giữa 2 digest gửi cùng một teacher
+}
+
+// Scheduler định kỳ tìm section còn empty/teacher đã quá hạn hoặc sắp đến hạn
+// và gửi một digest cho mỗi teacher. Cooldown lưu trong mongo nên restart / nhiều replica không gửi trùng.
+type Scheduler struct {
+	reportRepo   repository.ReportRepository
+	deadlineRepo repository.ReportDeadlineRepository
+	reminderRepo repository.ReportReminderRepository
+	schemas      schema.Registry
+	notifier     Notifier
+	clock        Clock
+	cfg          Config
+}
+
+func NewScheduler(
+	reportRepo repository.ReportRepository,
+	deadlineRepo repository.ReportDeadlineRepository,
+	reminderRepo repository.ReportReminderRepository,
+	schemas schema.Registry,
+	notifier Notifier,
+	clock Clock,
+	cfg Config,
+) *Scheduler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 24 * time.Hour
+	}
+	return &Scheduler{
+		reportRepo:   reportRepo,
+		deadlineRepo: deadlineRepo,
+		reminderRepo: reminderRepo,
+		schemas:      schemas,
+		notifier:     notifier,
+		clock:        clock,
+		cfg:          cfg,
+	}
+}
+
+// Start chạy đến khi ctx bị huỷ
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil {
+			logger.WriteLogEx("error", "report reminder run failed", map[string]interface{}{"error": err.Error()})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce quét một lượt, trả về số digest đã gửi
+func (s *Scheduler) RunOnce(ctx context.Context) (int, error) {
+	digests, err := s.collect(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.clock.Now()
+	sent := 0
+	for _, digest := range digests {
+		// claim trước khi gửi để replica khác không gửi cùng digest
+		claimed, err := s.reminderRepo.Claim(ctx, digest.OrganizationID, digest.TeacherID, now, s.cfg.Cooldown)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.notifier.Notify(ctx, *digest); err != nil {
+			logger.WriteLogEx("error", "send report reminder failed", map[string]interface{}{
+				"teacher_id": digest.TeacherID,
+				"error":      err.Error(),
+			})
+			if err := s.reminderRepo.Release(ctx, digest.OrganizationID, digest.TeacherID, now); err != nil {
+				logger.WriteLogEx("warn", "release report reminder failed", map[string]interface{}{
+					"teacher_id": digest.TeacherID,
+					"error":      err.Error(),
+				})
+			}
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// collect gom section cần nhắc theo (organization, teacher)
+func (s *Scheduler) collect(ctx context.Context) ([]*Digest, error) {
+	now := s.clock.Now()
+	from := now.Add(-s.cfg.OverdueWindow)
+	to := now.Add(s.cfg.Lookahead)
+
+	due, err := s.deadlineRepo.GetDueBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type orgTerm struct{ organizationID, termID string }
+	groups := make(map[orgTerm]bool)
+	for _, d := range due {
+		groups[orgTerm{d.OrganizationID, d.TermID}] = true
+	}
+
+	digests := make(map[string]*Digest)
+	for group := range groups {
+		// lấy đủ deadline của term để deadline cụ thể hơn (chưa đến hạn) được ưu tiên
+		deadlines, err := s.deadlineRepo.GetByOrganization(ctx, group.organizationID, group.termID)
+		if err != nil {
+			return nil, err
+		}
+		customSections, err := s.schemas.CustomSections(ctx, group.organizationID)
+		if err != nil {
+			return nil, err
+		}
+		reports, err := s.reportRepo.GetPendingByOrganizationAndTerm(ctx, group.organizationID, group.termID, pendingStatuses, missingSections(deadlines, customSections))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range reports {
+			for section, status := range pendingSections(r, deadlines, customSections) {
+				d := model.ResolveDeadline(deadlines, r.TopicID, section)
+				if d == nil || d.DueAt.Before(from) || d.DueAt.After(to) {
+					continue
+				}
+
+				key := group.organizationID + "|" + r.EditorID
+				digest, ok := digests[key]
+				if !ok {
+					digest = &Digest{TeacherID: r.EditorID, OrganizationID: group.organizationID, GeneratedAt: now}
+					digests[key] = digest
+				}
+
+				item := Item{
+					ReportID:  r.ID.Hex(),
+					StudentID: r.StudentID,
+					TopicID:   r.TopicID,
+					TermID:    r.TermID,
+					Language:  r.Language,
+					Section:   section,
+					Status:    status,
+					DueAt:     d.DueAt,
+					Overdue:   now.After(d.DueAt),
+				}
+				if item.Overdue {
+					digest.Overdue++
+				} else {
+					digest.DueSoon++
+				}
+				digest.Items = append(digest.Items, item)
+			}
+		}
+	}
+
+	res := make([]*Digest, 0, len(digests))
+	for _, digest := range digests {
+		sort.Slice(digest.Items, func(i, j int) bool {
+			a, b := digest.Items[i], digest.Items[j]
+			if !a.DueAt.Equal(b.DueAt) {
+				return a.DueAt.Before(b.DueAt)
+			}
+			if a.ReportID != b.ReportID {
+				return a.ReportID < b.ReportID
+			}
+			return a.Section < b.Section
+		})
+		res = append(res, digest)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].OrganizationID+res[i].TeacherID < res[j].OrganizationID+res[j].TeacherID
+	})
+	return res, nil
+}
+
+// pendingSections: section teacher chưa nộp (empty/teacher). Section có status mới tính,
+// riêng section tuỳ biến / section có deadline riêng mà chưa có dữ liệu thì coi là empty.
+func pendingSections(r *model.Report, deadlines []*model.ReportDeadline, customSections []*model.ReportSectionDefinition) map[string]string {
+	pending := make(map[string]string)
+
+	for section, data := range r.ReportData {
+		raw, ok := helper.ToBsonM(data)["status"].(string)
+		if !ok {
+			continue
+		}
+		if status, ok := workflow.ParseStatus(raw); ok && isPending(status) {
+			pending[section] = string(status)
+		}
+	}
+
+	empty := string(constants.SectionStatusEmpty)
+	for _, def := range customSections {
+		if _, ok := r.ReportData[def.Key]; !ok {
+			pending[def.Key] = empty
+		}
+	}
+	for _, d := range deadlines {
+		if d.Section == "" || (d.TopicID != "" && d.TopicID != r.TopicID) {
+			continue
+		}
+		if _, ok := r.ReportData[d.Section]; !ok {
+			pending[d.Section] = empty
+		}
+	}
+
+	return pending
+}
+
+// pendingStatuses: giá trị status thô được coi là chưa nộp, "" tương đương empty
+var pendingStatuses = []string{"", string(constants.SectionStatusEmpty), string(constants.SectionStatusTeacher)}
+
+// missingSections: section chưa có dữ liệu vẫn cần nhắc (section tuỳ biến, section có deadline riêng)
+func missingSections(deadlines []*model.ReportDeadline, customSections []*model.ReportSectionDefinition) []string {
+	seen := make(map[string]bool)
+	var sections []string
+	for _, def := range customSections {
+		if !seen[def.Key] {
+			seen[def.Key] = true
+			sections = append(sections, def.Key)
+		}
+	}
+	for _, d := range deadlines {
+		if d.Section != "" && !seen[d.Section] {
+			seen[d.Section] = true
+			sections = append(sections, d.Section)
+		}
+	}
+	return sections
+}
+
+func isPending(status constants.SectionStatus) bool {
+	return status == constants.SectionStatusEmpty || status == constants.SectionStatusTeacher
+}
@@ -0,0 +1,296 @@
+package reminder
+
+import (
+	"context"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeReportRepo chỉ lọc theo organization + term, phần lọc status do pendingSections của scheduler làm
+type fakeReportRepo struct {
+	repository.ReportRepository
+	reports []*model.Report
+}
+
+func (r *fakeReportRepo) GetPendingByOrganizationAndTerm(ctx context.Context, organizationID, termID string, statuses, missingSections []string) ([]*model.Report, error) {
+	var res []*model.Report
+	for _, report := range r.reports {
+		if report.OrganizationID == organizationID && report.TermID == termID && report.EditorID != "" {
+			res = append(res, report)
+		}
+	}
+	return res, nil
+}
+
+type fakeDeadlineRepo struct {
+	repository.ReportDeadlineRepository
+	deadlines []*model.ReportDeadline
+}
+
+func (r *fakeDeadlineRepo) GetByOrganization(ctx context.Context, organizationID, termID string) ([]*model.ReportDeadline, error) {
+	var res []*model.ReportDeadline
+	for _, d := range r.deadlines {
+		if d.OrganizationID == organizationID && d.TermID == termID {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+func (r *fakeDeadlineRepo) GetDueBetween(ctx context.Context, from, to time.Time) ([]*model.ReportDeadline, error) {
+	var res []*model.ReportDeadline
+	for _, d := range r.deadlines {
+		if !d.DueAt.Before(from) && !d.DueAt.After(to) {
+			res = append(res, d)
+		}
+	}
+	return res, nil
+}
+
+// fakeReminderRepo giữ last_sent_at trong map, cùng điều kiện cooldown với bản mongo
+type fakeReminderRepo struct {
+	lastSent map[string]time.Time
+}
+
+func newFakeReminderRepo() *fakeReminderRepo {
+	return &fakeReminderRepo{lastSent: make(map[string]time.Time)}
+}
+
+func (r *fakeReminderRepo) Claim(ctx context.Context, organizationID, teacherID string, now time.Time, cooldown time.Duration) (bool, error) {
+	key := organizationID + "|" + teacherID
+	if last, ok := r.lastSent[key]; ok && last.After(now.Add(-cooldown)) {
+		return false, nil
+	}
+	r.lastSent[key] = now
+	return true, nil
+}
+
+func (r *fakeReminderRepo) Release(ctx context.Context, organizationID, teacherID string, claimedAt time.Time) error {
+	key := organizationID + "|" + teacherID
+	if r.lastSent[key].Equal(claimedAt) {
+		delete(r.lastSent, key)
+	}
+	return nil
+}
+
+func (r *fakeReminderRepo) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+type fakeRegistry struct{}
+
+func (fakeRegistry) Get(ctx context.Context, organizationID string) (model.ReportSchema, error) {
+	return model.ReportSchema{}, nil
+}
+
+func (fakeRegistry) Validate(ctx context.Context, organizationID string, data map[string]interface{}) error {
+	return nil
+}
+
+func (fakeRegistry) CustomSections(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error) {
+	return nil, nil
+}
+
+type recordingNotifier struct {
+	digests []Digest
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, digest Digest) error {
+	n.digests = append(n.digests, digest)
+	return nil
+}
+
+var testNow = time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC)
+
+func testConfig() Config {
+	return Config{
+		Interval:      time.Hour,
+		Lookahead:     48 * time.Hour,
+		OverdueWindow: 7 * 24 * time.Hour,
+		Cooldown:      24 * time.Hour,
+	}
+}
+
+func newTestScheduler(reports []*model.Report, deadlines []*model.ReportDeadline, clock Clock) (*Scheduler, *recordingNotifier) {
+	notifier := &recordingNotifier{}
+	s := NewScheduler(
+		&fakeReportRepo{reports: reports},
+		&fakeDeadlineRepo{deadlines: deadlines},
+		newFakeReminderRepo(),
+		fakeRegistry{},
+		notifier,
+		clock,
+		testConfig(),
+	)
+	return s, notifier
+}
+
+func sectionDeadline(organizationID, section string, dueAt time.Time) *model.ReportDeadline {
+	return &model.ReportDeadline{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: organizationID,
+		TermID:         "term-1",
+		Section:        section,
+		DueAt:          dueAt,
+	}
+}
+
+func testReport(organizationID, editorID string, data bson.M) *model.Report {
+	return &model.Report{
+		ID:             primitive.NewObjectID(),
+		OrganizationID: organizationID,
+		EditorID:       editorID,
+		StudentID:      "student-" + editorID,
+		TopicID:        "topic-1",
+		TermID:         "term-1",
+		Language:       "vi",
+		ReportData:     data,
+	}
+}
+
+func TestRunOnceDueWindow(t *testing.T) {
+	clock := NewFakeClock(testNow)
+	deadlines := []*model.ReportDeadline{
+		sectionDeadline("org-1", "goal", testNow.Add(24*time.Hour)),       // sắp đến hạn
+		sectionDeadline("org-1", "note", testNow.Add(-24*time.Hour)),      // quá hạn
+		sectionDeadline("org-1", "now", testNow.Add(72*time.Hour)),        // xa hơn lookahead
+		sectionDeadline("org-1", "before", testNow.Add(-10*24*time.Hour)), // quá hạn lâu hơn overdue window
+		sectionDeadline("org-1", "conclusion", testNow.Add(time.Hour)),    // đã gửi manager
+	}
+	report := testReport("org-1", "teacher-1", bson.M{
+		"goal":       bson.M{"status": "teacher"},
+		"note":       bson.M{"status": "Empty"},
+		"now":        bson.M{"status": "teacher"},
+		"before":     bson.M{"status": "teacher"},
+		"conclusion": bson.M{"status": "manager"},
+	})
+	s, notifier := newTestScheduler([]*model.Report{report}, deadlines, clock)
+
+	sent, err := s.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if sent != 1 || len(notifier.digests) != 1 {
+		t.Fatalf("sent = %d, digests = %d, want 1 digest", sent, len(notifier.digests))
+	}
+
+	digest := notifier.digests[0]
+	if digest.Overdue != 1 || digest.DueSoon != 1 {
+		t.Fatalf("overdue = %d, due soon = %d, want 1 and 1", digest.Overdue, digest.DueSoon)
+	}
+	want := []struct {
+		section string
+		status  string
+		overdue bool
+	}{
+		{"note", "empty", true},
+		{"goal", "teacher", false},
+	}
+	if len(digest.Items) != len(want) {
+		t.Fatalf("items = %+v, want %d items", digest.Items, len(want))
+	}
+	for i, w := range want {
+		item := digest.Items[i]
+		if item.Section != w.section || item.Status != w.status || item.Overdue != w.overdue {
+			t.Errorf("item %d = %s/%s overdue=%v, want %s/%s overdue=%v", i, item.Section, item.Status, item.Overdue, w.section, w.status, w.overdue)
+		}
+	}
+
+	// 2 ngày sau: goal đã quá hạn, now vào lookahead, note vẫn trong overdue window
+	clock.Advance(48 * time.Hour)
+	notifier.digests = nil
+	if _, err := s.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if len(notifier.digests) != 1 {
+		t.Fatalf("digests = %d, want 1", len(notifier.digests))
+	}
+	digest = notifier.digests[0]
+	if digest.Overdue != 2 || digest.DueSoon != 1 {
+		t.Fatalf("overdue = %d, due soon = %d, want 2 and 1", digest.Overdue, digest.DueSoon)
+	}
+}
+
+func TestRunOnceCooldown(t *testing.T) {
+	clock := NewFakeClock(testNow)
+	deadlines := []*model.ReportDeadline{
+		{ID: primitive.NewObjectID(), OrganizationID: "org-1", TermID: "term-1", DueAt: testNow.Add(time.Hour)},
+	}
+	report := testReport("org-1", "teacher-1", bson.M{"goal": bson.M{"status": "teacher"}})
+	s, _ := newTestScheduler([]*model.Report{report}, deadlines, clock)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		want    int
+	}{
+		{"first run", 0, 1},
+		{"same tick", 0, 0},
+		{"inside cooldown", 23 * time.Hour, 0},
+		{"cooldown passed", time.Hour, 1},
+		{"claimed again", 30 * time.Minute, 0},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		sent, err := s.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("%s: RunOnce: %v", step.name, err)
+		}
+		if sent != step.want {
+			t.Errorf("%s: sent = %d, want %d", step.name, sent, step.want)
+		}
+	}
+}
+
+func TestRunOnceGroupsDigests(t *testing.T) {
+	clock := NewFakeClock(testNow)
+	deadlines := []*model.ReportDeadline{
+		{ID: primitive.NewObjectID(), OrganizationID: "org-1", TermID: "term-1", DueAt: testNow.Add(time.Hour)},
+		{ID: primitive.NewObjectID(), OrganizationID: "org-2", TermID: "term-1", DueAt: testNow.Add(-time.Hour)},
+	}
+	reports := []*model.Report{
+		testReport("org-1", "teacher-a", bson.M{"goal": bson.M{"status": "teacher"}, "note": bson.M{"status": "empty"}}),
+		testReport("org-1", "teacher-a", bson.M{"goal": bson.M{"status": "teacher"}, "now": bson.M{"status": "done"}}),
+		testReport("org-1", "teacher-b", bson.M{"goal": bson.M{"status": "accepted"}}),
+		testReport("org-1", "teacher-c", bson.M{"goal": bson.M{"status": "teacher"}}),
+		testReport("org-1", "", bson.M{"goal": bson.M{"status": "teacher"}}),
+		testReport("org-2", "teacher-a", bson.M{"goal": bson.M{"status": "teacher"}}),
+	}
+	s, notifier := newTestScheduler(reports, deadlines, clock)
+
+	sent, err := s.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	want := []struct {
+		organizationID string
+		teacherID      string
+		items          int
+		overdue        int
+		dueSoon        int
+	}{
+		{"org-1", "teacher-a", 3, 0, 3},
+		{"org-1", "teacher-c", 1, 0, 1},
+		{"org-2", "teacher-a", 1, 1, 0},
+	}
+	if sent != len(want) || len(notifier.digests) != len(want) {
+		t.Fatalf("sent = %d, digests = %d, want %d", sent, len(notifier.digests), len(want))
+	}
+	for i, w := range want {
+		digest := notifier.digests[i]
+		if digest.OrganizationID != w.organizationID || digest.TeacherID != w.teacherID {
+			t.Errorf("digest %d = %s/%s, want %s/%s", i, digest.OrganizationID, digest.TeacherID, w.organizationID, w.teacherID)
+			continue
+		}
+		if len(digest.Items) != w.items || digest.Overdue != w.overdue || digest.DueSoon != w.dueSoon {
+			t.Errorf("digest %s/%s: items = %d overdue = %d due soon = %d, want %d/%d/%d",
+				w.organizationID, w.teacherID, len(digest.Items), digest.Overdue, digest.DueSoon, w.items, w.overdue, w.dueSoon)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// encodeCursor mã hoá vị trí (timestamp, _id) thành chuỗi opaque cho client
+func encodeCursor(ts time.Time, id primitive.ObjectID) string {
+	raw := strconv.FormatInt(ts.UnixMilli(), 10) + ":" + id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, primitive.ObjectID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+
+	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, ErrInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return time.Time{}, primitive.NilObjectID, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	return time.UnixMilli(ms).UTC(), id, nil
+}
+
+// syncTokenPrefix phân biệt sync token theo change_seq với token cũ theo updated_at
+const syncTokenPrefix = "c"
+
+// encodeSyncToken mã hoá vị trí (change_seq, _id) thành sync token cho app
+func encodeSyncToken(seq int64, id primitive.ObjectID) string {
+	raw := syncTokenPrefix + strconv.FormatInt(seq, 10) + ":" + id.Hex()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeSyncToken: ok = false khi là token cũ theo updated_at, client cần sync lại từ đầu
+func decodeSyncToken(token string) (int64, primitive.ObjectID, bool, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, primitive.NilObjectID, false, ErrInvalidCursor
+	}
+	if !strings.HasPrefix(string(raw), syncTokenPrefix) {
+		if _, _, err := decodeCursor(token); err != nil {
+			return 0, primitive.NilObjectID, false, err
+		}
+		return 0, primitive.NilObjectID, false, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(string(raw), syncTokenPrefix), ":", 2)
+	if len(parts) != 2 {
+		return 0, primitive.NilObjectID, false, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, primitive.NilObjectID, false, ErrInvalidCursor
+	}
+	id, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		return 0, primitive.NilObjectID, false, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return seq, id, true, nil
+}
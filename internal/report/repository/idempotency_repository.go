@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, record *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, userID, key string, code int, contentType string, body []byte) error
+	Release(ctx context.Context, userID, key string) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+// request đang xử lý quá thời gian này (server chết giữa chừng) thì cho request khác lấy lại key
+const idempotencyStaleAfter = time.Minute
+
+type idempotencyRepository struct {
+	collection *mongo.Collection
+	ttl        time.Duration
+}
+
+func NewIdempotencyRepository(collection *mongo.Collection, ttl time.Duration) IdempotencyRepository {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &idempotencyRepository{collection: collection, ttl: ttl}
+}
+
+// Reserve giữ key cho request hiện tại. Trả về nil nếu giữ được,
+// ngược lại trả về bản ghi đã có (đang xử lý hoặc đã có kết quả).
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	now := time.Now()
+	record.Status = constants.IdempotencyStatusProcessing
+	record.CreatedAt = now
+	record.ExpiresAt = now.Add(r.ttl)
+
+	_, err := r.collection.InsertOne(ctx, record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("reserve idempotency key failed: %w", err)
+	}
+
+	filter := bson.M{"user_id": record.UserID, "key": record.Key}
+
+	var existing model.IdempotencyKey
+	if err := r.collection.FindOne(ctx, filter).Decode(&existing); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// vừa hết hạn / bị release, thử lại một lần
+			if _, err := r.collection.InsertOne(ctx, record); err == nil {
+				return nil, nil
+			}
+		}
+		return nil, fmt.Errorf("get idempotency key failed: %w", err)
+	}
+
+	// key đã hết hạn (TTL monitor chưa kịp xoá) hoặc bản ghi processing bị treo thì lấy lại key
+	expired := now.After(existing.ExpiresAt)
+	stale := existing.Status == constants.IdempotencyStatusProcessing && existing.RequestHash == record.RequestHash && now.Sub(existing.CreatedAt) > idempotencyStaleAfter
+	if expired || stale {
+		res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": existing.ID, "created_at": existing.CreatedAt}, record)
+		if err != nil {
+			return nil, fmt.Errorf("take over idempotency key failed: %w", err)
+		}
+		if res.MatchedCount == 1 {
+			return nil, nil
+		}
+	}
+
+	return &existing, nil
+}
+
+// Complete lưu response để các lần gửi lại được replay
+func (r *idempotencyRepository) Complete(ctx context.Context, userID, key string, code int, contentType string, body []byte) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"user_id": userID, "key": key},
+		bson.M{"$set": bson.M{
+			"status":        constants.IdempotencyStatusCompleted,
+			"response_code": code,
+			"content_type":  contentType,
+			"response_body": body,
+		}},
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key failed: %w", err)
+	}
+	return nil
+}
+
+// Release xoá key khi request lỗi phía server để client có thể thử lại
+func (r *idempotencyRepository) Release(ctx context.Context, userID, key string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID, "key": key, "status": constants.IdempotencyStatusProcessing})
+	if err != nil {
+		return fmt.Errorf("release idempotency key failed: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrDeadlineNotFound = errors.New("report deadline not found")
+
+type ReportDeadlineRepository interface {
+	GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportDeadline, error)
+	GetByOrganization(ctx context.Context, organizationID, termID string) ([]*model.ReportDeadline, error)
+	GetDueBetween(ctx context.Context, from, to time.Time) ([]*model.ReportDeadline, error)
+	Create(ctx context.Context, deadline *model.ReportDeadline) error
+	Update(ctx context.Context, deadline *model.ReportDeadline) error
+	Delete(ctx context.Context, organizationID string, id primitive.ObjectID) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type reportDeadlineRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportDeadlineRepository(collection *mongo.Collection) ReportDeadlineRepository {
+	return &reportDeadlineRepository{collection}
+}
+
+func (r *reportDeadlineRepository) GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportDeadline, error) {
+	var deadline model.ReportDeadline
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "organization_id": organizationID}).Decode(&deadline)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrDeadlineNotFound
+		}
+		return nil, err
+	}
+	return &deadline, nil
+}
+
+// GetByOrganization trả về deadline của organization theo due_at tăng dần, termID rỗng = mọi term
+func (r *reportDeadlineRepository) GetByOrganization(ctx context.Context, organizationID, termID string) ([]*model.ReportDeadline, error) {
+	filter := bson.M{"organization_id": organizationID}
+	if termID != "" {
+		filter["term_id"] = termID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "due_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var deadlines []*model.ReportDeadline
+	if err := cursor.All(ctx, &deadlines); err != nil {
+		return nil, err
+	}
+	return deadlines, nil
+}
+
+// GetDueBetween: deadline của mọi organization có due_at trong [from, to], dùng cho job nhắc hạn
+func (r *reportDeadlineRepository) GetDueBetween(ctx context.Context, from, to time.Time) ([]*model.ReportDeadline, error) {
+	filter := bson.M{"due_at": bson.M{"$gte": from, "$lte": to}}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var deadlines []*model.ReportDeadline
+	if err := cursor.All(ctx, &deadlines); err != nil {
+		return nil, err
+	}
+	return deadlines, nil
+}
+
+func (r *reportDeadlineRepository) Create(ctx context.Context, deadline *model.ReportDeadline) error {
+	now := time.Now()
+	deadline.ID = primitive.NewObjectID()
+	deadline.CreatedAt = now
+	deadline.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, deadline)
+	return err
+}
+
+func (r *reportDeadlineRepository) Update(ctx context.Context, deadline *model.ReportDeadline) error {
+	deadline.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"term_id":    deadline.TermID,
+			"topic_id":   deadline.TopicID,
+			"section":    deadline.Section,
+			"due_at":     deadline.DueAt,
+			"updated_by": deadline.UpdatedBy,
+			"updated_at": deadline.UpdatedAt,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": deadline.ID, "organization_id": deadline.OrganizationID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrDeadlineNotFound
+	}
+	return nil
+}
+
+func (r *reportDeadlineRepository) Delete(ctx context.Context, organizationID string, id primitive.ObjectID) error {
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "organization_id": organizationID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrDeadlineNotFound
+	}
+	return nil
+}
+
+func (r *reportDeadlineRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "term_id", Value: 1}, {Key: "due_at", Value: 1}},
+	})
+	return err
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"report-service/internal/report/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrLockHeld    = errors.New("report is being edited by another user")
+	ErrLockNotHeld = errors.New("editing lock is not held by this user")
+)
+
+// LockHeldError: report đang được người khác giữ lock, kèm thông tin holder
+type LockHeldError struct {
+	Lock *model.EditingLock
+}
+
+func (e *LockHeldError) Error() string {
+	holder := e.Lock.HolderName
+	if holder == "" {
+		holder = e.Lock.HolderID
+	}
+	return fmt.Sprintf("%v: %s (%s) until %s", ErrLockHeld, holder, e.Lock.Client, e.Lock.ExpiresAt.Format(time.RFC3339))
+}
+
+func (e *LockHeldError) Unwrap() error {
+	return ErrLockHeld
+}
+
+// AcquireEditingLock lấy lock cho holder nếu report chưa bị lock, lock đã hết hạn hoặc holder đang giữ (khi đó chỉ gia hạn)
+func (r *reportRepository) AcquireEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock, ttl time.Duration) (*model.EditingLock, error) {
+	lock, err := r.RenewEditingLock(ctx, reportID, holder, ttl)
+	if !errors.Is(err, ErrLockNotHeld) {
+		return lock, err
+	}
+
+	now := time.Now()
+	holder.AcquiredAt = now
+	holder.ExpiresAt = now.Add(ttl)
+
+	filter := bson.M{
+		"_id": reportID,
+		"$or": bson.A{
+			bson.M{"editing_lock": nil},
+			bson.M{"editing_lock.expires_at": bson.M{"$lte": now}},
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"editing_lock": holder}})
+	if err != nil {
+		return nil, fmt.Errorf("acquire editing lock failed: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return nil, r.lockError(ctx, reportID, holder)
+	}
+
+	return &holder, nil
+}
+
+// RenewEditingLock gia hạn lock holder đang giữ (kể cả khi vừa hết hạn mà chưa ai lấy)
+func (r *reportRepository) RenewEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock, ttl time.Duration) (*model.EditingLock, error) {
+	filter := bson.M{
+		"_id":                    reportID,
+		"editing_lock.holder_id": holder.HolderID,
+		"editing_lock.client":    holder.Client,
+	}
+
+	var updated model.Report
+	err := r.collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"editing_lock.expires_at": time.Now().Add(ttl)}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, r.lockError(ctx, reportID, holder)
+		}
+		return nil, fmt.Errorf("renew editing lock failed: %w", err)
+	}
+
+	return updated.Lock, nil
+}
+
+// ReleaseEditingLock trả lock, không làm gì nếu holder không giữ lock
+func (r *reportRepository) ReleaseEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock) error {
+	filter := bson.M{
+		"_id":                    reportID,
+		"editing_lock.holder_id": holder.HolderID,
+		"editing_lock.client":    holder.Client,
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, bson.M{"$unset": bson.M{"editing_lock": ""}}); err != nil {
+		return fmt.Errorf("release editing lock failed: %w", err)
+	}
+	return nil
+}
+
+// lockError xác định lý do không lấy/gia hạn được lock: report không tồn tại, người khác đang giữ, hoặc holder không giữ lock
+func (r *reportRepository) lockError(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock) error {
+	current, err := r.findCurrent(ctx, bson.M{"_id": reportID})
+	if err != nil {
+		return fmt.Errorf("get current report failed: %w", err)
+	}
+	if current == nil {
+		return mongo.ErrNoDocuments
+	}
+	if err := checkEditingLock(current, &holder); err != nil {
+		return err
+	}
+	return ErrLockNotHeld
+}
+
+// checkEditingLock báo lỗi nếu report đang bị người khác (hoặc client khác) giữ lock còn hiệu lực
+func checkEditingLock(current *model.Report, writer *model.EditingLock) error {
+	if current == nil || writer == nil {
+		return nil
+	}
+	if current.Lock.Active(time.Now()) && !current.Lock.HeldBy(writer.HolderID, writer.Client) {
+		return &LockHeldError{Lock: current.Lock}
+	}
+	return nil
+}
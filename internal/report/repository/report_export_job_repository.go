@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrExportJobNotFound = errors.New("report export job not found")
+
+type ReportExportJobRepository interface {
+	Create(ctx context.Context, job *model.ReportExportJob) error
+	GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportExportJob, error)
+	Update(ctx context.Context, job *model.ReportExportJob) error
+	FailUnfinished(ctx context.Context, reason string) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type reportExportJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportExportJobRepository(collection *mongo.Collection) ReportExportJobRepository {
+	return &reportExportJobRepository{collection}
+}
+
+func (r *reportExportJobRepository) Create(ctx context.Context, job *model.ReportExportJob) error {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, job)
+	return err
+}
+
+func (r *reportExportJobRepository) GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportExportJob, error) {
+	var job model.ReportExportJob
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "organization_id": organizationID}).Decode(&job)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrExportJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Update ghi lại trạng thái và tiến độ của job
+func (r *reportExportJobRepository) Update(ctx context.Context, job *model.ReportExportJob) error {
+	job.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":        job.Status,
+			"total":         job.Total,
+			"processed":     job.Processed,
+			"failed":        job.Failed,
+			"error":         job.Error,
+			"artifact_key":  job.ArtifactKey,
+			"artifact_size": job.ArtifactSize,
+			"file_name":     job.FileName,
+			"updated_at":    job.UpdatedAt,
+			"started_at":    job.StartedAt,
+			"finished_at":   job.FinishedAt,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": job.ID}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrExportJobNotFound
+	}
+	return nil
+}
+
+// FailUnfinished: queue nằm trong bộ nhớ nên job dở dang khi khởi động lại sẽ không chạy tiếp
+func (r *reportExportJobRepository) FailUnfinished(ctx context.Context, reason string) (int64, error) {
+	now := time.Now()
+	filter := bson.M{
+		"status": bson.M{"$in": []string{string(constants.ReportExportJobQueued), string(constants.ReportExportJobRunning)}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      string(constants.ReportExportJobFailed),
+			"error":       reason,
+			"updated_at":  now,
+			"finished_at": now,
+		},
+	}
+
+	res, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+func (r *reportExportJobRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// missingOrganization: document ghi trước khi có organization_id
+var missingOrganization = bson.M{"organization_id": bson.M{"$in": bson.A{nil, ""}}}
+
+// OrganizationsByStudent lấy organization của từng học sinh từ các history đã có organization_id,
+// học sinh chuyển organization thì lấy organization của history mới nhất
+func (r *reportHistoryRepository) OrganizationsByStudent(ctx context.Context) (map[string]string, error) {
+	cur, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organization_id": bson.M{"$nin": bson.A{nil, ""}}}}},
+		// history cũ chưa có student_id ở cấp ngoài
+		{{Key: "$project", Value: bson.M{
+			"student_id":      bson.M{"$ifNull": bson.A{"$student_id", "$report.student_id"}},
+			"organization_id": 1,
+			"timestamp":       1,
+		}}},
+		{{Key: "$match", Value: bson.M{"student_id": bson.M{"$nin": bson.A{nil, ""}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$student_id", "organization_id": bson.M{"$last": "$organization_id"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get history organizations failed: %w", err)
+	}
+
+	var rows []struct {
+		StudentID      string `bson:"_id"`
+		OrganizationID string `bson:"organization_id"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	organizations := make(map[string]string, len(rows))
+	for _, row := range rows {
+		organizations[row.StudentID] = row.OrganizationID
+	}
+	return organizations, nil
+}
+
+// BackfillOrganization gắn organization_id cho history ghi trước khi có field này theo học sinh của history,
+// trả về số history đã cập nhật. History của học sinh không có trong map thì giữ nguyên.
+func (r *reportHistoryRepository) BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error) {
+	var updated int64
+	for studentID, organizationID := range organizationByStudent {
+		filter := bson.M{"$and": bson.A{
+			missingOrganization,
+			bson.M{"$or": bson.A{
+				bson.M{"student_id": studentID},
+				bson.M{"report.student_id": studentID},
+			}},
+		}}
+		res, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"organization_id": organizationID}})
+		if err != nil {
+			return updated, fmt.Errorf("backfill history organization of student %s failed: %w", studentID, err)
+		}
+		updated += res.ModifiedCount
+	}
+	return updated, nil
+}
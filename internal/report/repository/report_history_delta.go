@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"report-service/internal/report/diff"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxSeqAttempts: số lần cấp lại seq khi 2 history của cùng report được lưu đồng thời
+const maxSeqAttempts = 5
+
+// Create lưu history với seq tăng dần theo report, seq trùng (bị ghi chen) thì đọc lại và cấp seq mới.
+// Ở mode delta chỉ lưu phần thay đổi so với entry trước, cứ mỗi CheckpointInterval entry thì lưu 1 bản full (checkpoint).
+func (r *reportHistoryRepository) Create(ctx context.Context, history *model.ReportHistory) error {
+	if history.ReportID.IsZero() {
+		_, err := r.collection.InsertOne(ctx, history)
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := r.insertNext(ctx, history)
+		if mongo.IsDuplicateKeyError(err) && attempt < maxSeqAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// insertNext lưu history với seq = seq mới nhất + 1, unique index (report_id, seq) chặn 2 entry cùng seq
+func (r *reportHistoryRepository) insertNext(ctx context.Context, history *model.ReportHistory) error {
+	latest, err := r.findLatest(ctx, history.ReportID)
+	if err != nil {
+		return err
+	}
+
+	history.Mode = constants.HistoryStorageFull
+	history.Seq = 0
+	if latest != nil {
+		history.Seq = latest.Seq + 1
+	}
+
+	if r.storage.Mode == constants.HistoryStorageDelta && history.Report != nil &&
+		latest != nil && history.Seq%int64(r.storage.CheckpointInterval) != 0 {
+		previous, err := r.Reconstruct(ctx, latest)
+		if err == nil && previous != nil {
+			delta, err := diff.ReportDelta(previous, history.Report)
+			if err == nil {
+				stored := *history
+				stored.Mode = constants.HistoryStorageDelta
+				stored.Delta = delta
+				stored.Report = nil
+
+				_, err = r.collection.InsertOne(ctx, &stored)
+				return err
+			}
+		}
+	}
+
+	_, err = r.collection.InsertOne(ctx, history)
+	return err
+}
+
+// Reconstruct dựng lại report tại thời điểm của history (checkpoint gần nhất + các delta)
+func (r *reportHistoryRepository) Reconstruct(ctx context.Context, history *model.ReportHistory) (*model.Report, error) {
+	if history.Mode != constants.HistoryStorageDelta {
+		return history.Report, nil
+	}
+
+	// checkpoint gần nhất trước history, history cũ chưa có seq được coi là seq 0
+	var checkpoint model.ReportHistory
+	err := r.collection.FindOne(ctx,
+		bson.M{
+			"report_id": history.ReportID,
+			"mode":      bson.M{"$ne": constants.HistoryStorageDelta},
+			"$or": bson.A{
+				bson.M{"seq": bson.M{"$lte": history.Seq}},
+				bson.M{"seq": bson.M{"$exists": false}},
+			},
+		},
+		options.FindOne().SetSort(seqDesc),
+	).Decode(&checkpoint)
+	if err != nil {
+		return nil, fmt.Errorf("find history checkpoint failed: %w", err)
+	}
+
+	// các delta từ checkpoint tới history
+	cur, err := r.collection.Find(ctx,
+		bson.M{
+			"report_id": history.ReportID,
+			"mode":      constants.HistoryStorageDelta,
+			"seq":       bson.M{"$gt": checkpoint.Seq, "$lte": history.Seq},
+		},
+		options.Find().SetSort(seqAsc),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var deltas []*model.ReportHistory
+	if err := cur.All(ctx, &deltas); err != nil {
+		return nil, err
+	}
+
+	report := checkpoint.Report
+	for _, d := range deltas {
+		report, err = diff.ApplyDelta(report, d.Delta)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// Compact chuyển các history full snapshot cũ của 1 report sang dạng delta, giữ lại checkpoint định kỳ.
+// Chạy offline (cmd/compact-history) vì seq được đánh số lại. Trả về số entry đã được chuyển.
+func (r *reportHistoryRepository) Compact(ctx context.Context, reportID primitive.ObjectID) (int, error) {
+	cur, err := r.collection.Find(ctx, bson.M{"report_id": reportID}, options.Find().SetSort(seqAsc))
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close(ctx)
+
+	var histories []*model.ReportHistory
+	if err := cur.All(ctx, &histories); err != nil {
+		return 0, err
+	}
+
+	// đánh số lại qua seq âm tạm thời để không đụng unique index (report_id, seq)
+	for i, h := range histories {
+		if h.Seq != int64(i) {
+			if _, err := r.collection.UpdateByID(ctx, h.ID, bson.M{"$set": bson.M{"seq": -int64(i) - 1}}); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	compacted := 0
+	var state *model.Report
+
+	for i, h := range histories {
+		seq := int64(i)
+
+		if h.Mode == constants.HistoryStorageDelta {
+			if state == nil {
+				return compacted, fmt.Errorf("history %s has no checkpoint before it", h.ID.Hex())
+			}
+			state, err = diff.ApplyDelta(state, h.Delta)
+			if err != nil {
+				return compacted, err
+			}
+			if h.Seq != seq {
+				if _, err := r.collection.UpdateByID(ctx, h.ID, bson.M{"$set": bson.M{"seq": seq}}); err != nil {
+					return compacted, err
+				}
+			}
+			continue
+		}
+
+		// giữ bản full làm checkpoint, entry không có snapshot cũng là checkpoint (rỗng)
+		// nên state reset theo, giống cách Reconstruct đọc lại
+		if state == nil || h.Report == nil || seq%int64(r.storage.CheckpointInterval) == 0 {
+			update := bson.M{"$set": bson.M{"mode": constants.HistoryStorageFull, "seq": seq}}
+			if _, err := r.collection.UpdateByID(ctx, h.ID, update); err != nil {
+				return compacted, err
+			}
+			state = h.Report
+			continue
+		}
+
+		delta, err := diff.ReportDelta(state, h.Report)
+		if err != nil {
+			return compacted, err
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"mode":       constants.HistoryStorageDelta,
+				"seq":        seq,
+				"delta":      delta,
+				"student_id": h.Report.StudentID,
+			},
+			"$unset": bson.M{"report": ""},
+		}
+		if _, err := r.collection.UpdateByID(ctx, h.ID, update); err != nil {
+			return compacted, err
+		}
+
+		state = h.Report
+		compacted++
+	}
+
+	return compacted, nil
+}
+
+func (r *reportHistoryRepository) CompactAll(ctx context.Context) (int, error) {
+	reportIDs, err := r.collection.Distinct(ctx, "report_id", bson.M{"report": bson.M{"$exists": true}})
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, id := range reportIDs {
+		reportID, ok := id.(primitive.ObjectID)
+		if !ok || reportID.IsZero() {
+			continue
+		}
+		n, err := r.Compact(ctx, reportID)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("compact report %s failed: %w", reportID.Hex(), err)
+		}
+	}
+
+	return total, nil
+}
+
+// hydrate điền Report cho các history lưu dạng delta. Cả trang dùng chung 2 query:
+// checkpoint gần nhất của từng report rồi toàn bộ entry từ checkpoint tới entry mới nhất trong trang,
+// gặp checkpoint nằm giữa khoảng thì dựng lại từ checkpoint đó giống Reconstruct.
+func (r *reportHistoryRepository) hydrate(ctx context.Context, histories []*model.ReportHistory) ([]*model.ReportHistory, error) {
+	type seqRange struct{ min, max int64 }
+	ranges := make(map[primitive.ObjectID]*seqRange)
+	for _, h := range histories {
+		if h.Mode != constants.HistoryStorageDelta {
+			continue
+		}
+		if rg, ok := ranges[h.ReportID]; ok {
+			rg.min = min(rg.min, h.Seq)
+			rg.max = max(rg.max, h.Seq)
+		} else {
+			ranges[h.ReportID] = &seqRange{min: h.Seq, max: h.Seq}
+		}
+	}
+	if len(ranges) == 0 {
+		return histories, nil
+	}
+
+	// checkpoint gần nhất trước entry cũ nhất của mỗi report, history cũ chưa có seq được coi là seq 0
+	checkpointConds := make(bson.A, 0, len(ranges))
+	for reportID, rg := range ranges {
+		checkpointConds = append(checkpointConds, bson.M{
+			"report_id": reportID,
+			"mode":      bson.M{"$ne": constants.HistoryStorageDelta},
+			"$or": bson.A{
+				bson.M{"seq": bson.M{"$lte": rg.min}},
+				bson.M{"seq": bson.M{"$exists": false}},
+			},
+		})
+	}
+	cur, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"$or": checkpointConds}}},
+		{{Key: "$sort", Value: seqDesc}},
+		{{Key: "$group", Value: bson.M{"_id": "$report_id", "checkpoint": bson.M{"$first": "$$ROOT"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("find history checkpoints failed: %w", err)
+	}
+	var groups []struct {
+		Checkpoint model.ReportHistory `bson:"checkpoint"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	checkpoints := make(map[primitive.ObjectID]*model.ReportHistory, len(groups))
+	entryConds := make(bson.A, 0, len(groups))
+	for i := range groups {
+		cp := &groups[i].Checkpoint
+		checkpoints[cp.ReportID] = cp
+		entryConds = append(entryConds, bson.M{
+			"report_id": cp.ReportID,
+			"seq":       bson.M{"$gt": cp.Seq, "$lte": ranges[cp.ReportID].max},
+		})
+	}
+	for reportID := range ranges {
+		if _, ok := checkpoints[reportID]; !ok {
+			return nil, fmt.Errorf("find history checkpoint failed: report %s has no checkpoint", reportID.Hex())
+		}
+	}
+
+	cur, err = r.collection.Find(ctx, bson.M{"$or": entryConds}, options.Find().SetSort(seqAsc))
+	if err != nil {
+		return nil, err
+	}
+	var entries []*model.ReportHistory
+	if err := cur.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	// áp delta nối tiếp theo từng report, entry full thay luôn state
+	states := make(map[primitive.ObjectID]*model.Report, len(checkpoints))
+	for reportID, cp := range checkpoints {
+		states[reportID] = cp.Report
+	}
+	reports := make(map[primitive.ObjectID]*model.Report, len(entries))
+	for _, e := range entries {
+		if e.Mode != constants.HistoryStorageDelta {
+			states[e.ReportID] = e.Report
+			continue
+		}
+		state, err := diff.ApplyDelta(states[e.ReportID], e.Delta)
+		if err != nil {
+			return nil, err
+		}
+		states[e.ReportID] = state
+		reports[e.ID] = state
+	}
+
+	for _, h := range histories {
+		if h.Mode == constants.HistoryStorageDelta {
+			h.Report = reports[h.ID]
+		}
+	}
+	return histories, nil
+}
+
+// hydrateChain giống hydrate nhưng histories là toàn bộ chuỗi của 1 report theo thứ tự seq,
+// nên chỉ cần áp delta nối tiếp nhau
+func (r *reportHistoryRepository) hydrateChain(ctx context.Context, histories []*model.ReportHistory) ([]*model.ReportHistory, error) {
+	var previous *model.Report
+	for _, h := range histories {
+		if h.Mode == constants.HistoryStorageDelta {
+			var err error
+			if previous != nil {
+				h.Report, err = diff.ApplyDelta(previous, h.Delta)
+			} else {
+				h.Report, err = r.Reconstruct(ctx, h)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		previous = h.Report
+	}
+	return histories, nil
+}
+
+func (r *reportHistoryRepository) findLatest(ctx context.Context, reportID primitive.ObjectID) (*model.ReportHistory, error) {
+	cur, err := r.collection.Find(ctx,
+		bson.M{"report_id": reportID},
+		options.Find().SetSort(seqDesc).SetLimit(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var histories []*model.ReportHistory
+	if err := cur.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+	if len(histories) == 0 {
+		return nil, nil
+	}
+	return histories[0], nil
+}
+
+// thứ tự entry trong chuỗi history của 1 report, history cũ chưa có seq xếp theo thời gian
+var (
+	seqAsc  = bson.D{{Key: "seq", Value: 1}, {Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}
+	seqDesc = bson.D{{Key: "seq", Value: -1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}
+)
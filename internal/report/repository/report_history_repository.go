@@ -2,29 +2,63 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"report-service/internal/report/model"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ReportHistoryRepository interface {
 	Create(ctx context.Context, history *model.ReportHistory) error
 	GetAll(ctx context.Context) ([]*model.ReportHistory, error)
 	GetByEditor(ctx context.Context, editorID string, editorRole string) ([]*model.ReportHistory, error)
+	GetByReportID(ctx context.Context, reportID primitive.ObjectID) ([]*model.ReportHistory, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*model.ReportHistory, error)
+	GetByReportVersion(ctx context.Context, reportID primitive.ObjectID, version int64) (*model.ReportHistory, error)
+	Search(ctx context.Context, filter ReportHistoryFilter, cursor string, limit int64) ([]*model.ReportHistory, string, error)
+	EnsureIndexes(ctx context.Context) error
+	Reconstruct(ctx context.Context, history *model.ReportHistory) (*model.Report, error)
+	Compact(ctx context.Context, reportID primitive.ObjectID) (int, error)
+	CompactAll(ctx context.Context) (int, error)
+	OrganizationsByStudent(ctx context.Context) (map[string]string, error)
+	BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error)
 }
 
-type reportHistoryRepository struct {
-	collection *mongo.Collection
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type ReportHistoryFilter struct {
+	// OrganizationID bắt buộc, chỉ search trong organization của người gọi
+	OrganizationID string
+	ReportID       *primitive.ObjectID
+	StudentID      string
+	ClassroomID    string
+	EditorID       string
+	EditorRole     string
+	Type           string
+	From           *time.Time
+	To             *time.Time
 }
 
-func NewReportHistoryRepository(collection *mongo.Collection) ReportHistoryRepository {
-	return &reportHistoryRepository{collection}
+// HistoryStorage cấu hình cách lưu history: full snapshot hoặc delta + checkpoint định kỳ
+type HistoryStorage struct {
+	Mode               string
+	CheckpointInterval int
 }
 
-func (r *reportHistoryRepository) Create(ctx context.Context, history *model.ReportHistory) error {
-	_, err := r.collection.InsertOne(ctx, history)
-	return err
+type reportHistoryRepository struct {
+	collection *mongo.Collection
+	storage    HistoryStorage
+}
+
+func NewReportHistoryRepository(collection *mongo.Collection, storage HistoryStorage) ReportHistoryRepository {
+	if storage.CheckpointInterval <= 0 {
+		storage.CheckpointInterval = 20
+	}
+	return &reportHistoryRepository{collection: collection, storage: storage}
 }
 
 func (r *reportHistoryRepository) GetAll(ctx context.Context) ([]*model.ReportHistory, error) {
@@ -39,7 +73,7 @@ func (r *reportHistoryRepository) GetAll(ctx context.Context) ([]*model.ReportHi
 		return nil, err
 	}
 
-	return histories, nil
+	return r.hydrate(ctx, histories)
 }
 
 func (r *reportHistoryRepository) GetByEditor(ctx context.Context, editorID string, editorRole string) ([]*model.ReportHistory, error) {
@@ -54,5 +88,154 @@ func (r *reportHistoryRepository) GetByEditor(ctx context.Context, editorID stri
 		return nil, err
 	}
 
-	return histories, nil
+	return r.hydrate(ctx, histories)
+}
+
+func (r *reportHistoryRepository) GetByReportID(ctx context.Context, reportID primitive.ObjectID) ([]*model.ReportHistory, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"report_id": reportID}, options.Find().SetSort(seqAsc))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var histories []*model.ReportHistory
+	if err := cursor.All(ctx, &histories); err != nil {
+		return nil, err
+	}
+
+	return r.hydrateChain(ctx, histories)
+}
+
+func (r *reportHistoryRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*model.ReportHistory, error) {
+	var history model.ReportHistory
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&history); err != nil {
+		return nil, err
+	}
+	if _, err := r.hydrate(ctx, []*model.ReportHistory{&history}); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// GetByReportVersion lấy history có snapshot đúng version của report (bản ghi mới nhất nếu trùng)
+func (r *reportHistoryRepository) GetByReportVersion(ctx context.Context, reportID primitive.ObjectID, version int64) (*model.ReportHistory, error) {
+	opts := options.FindOne().SetSort(seqDesc)
+
+	var history model.ReportHistory
+	if err := r.collection.FindOne(ctx, bson.M{"report_id": reportID, "version": version}, opts).Decode(&history); err != nil {
+		return nil, err
+	}
+	if _, err := r.hydrate(ctx, []*model.ReportHistory{&history}); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// Search tìm history theo filter, mới nhất trước, phân trang bằng cursor (timestamp + _id của item cuối trang trước)
+// History ghi trước khi có organization_id chỉ tìm thấy sau khi chạy cmd/backfill-organization,
+// học sinh chưa có history nào mang organization thì history cũ của học sinh đó vẫn không hiện.
+func (r *reportHistoryRepository) Search(ctx context.Context, filter ReportHistoryFilter, cursor string, limit int64) ([]*model.ReportHistory, string, error) {
+	if filter.OrganizationID == "" {
+		return nil, "", errors.New("organization is required")
+	}
+	conditions := bson.A{bson.M{"organization_id": filter.OrganizationID}}
+
+	if filter.ReportID != nil {
+		conditions = append(conditions, bson.M{"report_id": *filter.ReportID})
+	}
+	if filter.StudentID != "" {
+		// history cũ chưa có student_id ở cấp ngoài
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"student_id": filter.StudentID},
+			bson.M{"report.student_id": filter.StudentID},
+		}})
+	}
+	if filter.ClassroomID != "" {
+		conditions = append(conditions, bson.M{"classroom_id": filter.ClassroomID})
+	}
+	if filter.EditorID != "" {
+		conditions = append(conditions, bson.M{"editor_id": filter.EditorID})
+	}
+	if filter.EditorRole != "" {
+		conditions = append(conditions, bson.M{"editor_role": filter.EditorRole})
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, bson.M{"type": filter.Type})
+	}
+
+	timeRange := bson.M{}
+	if filter.From != nil {
+		timeRange["$gte"] = *filter.From
+	}
+	if filter.To != nil {
+		timeRange["$lte"] = *filter.To
+	}
+	if len(timeRange) > 0 {
+		conditions = append(conditions, bson.M{"timestamp": timeRange})
+	}
+
+	if cursor != "" {
+		ts, id, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		conditions = append(conditions, bson.M{"$or": bson.A{
+			bson.M{"timestamp": bson.M{"$lt": ts}},
+			bson.M{"timestamp": ts, "_id": bson.M{"$lt": id}},
+		}})
+	}
+
+	query := bson.M{"$and": conditions}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit + 1)
+
+	cur, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cur.Close(ctx)
+
+	var histories []*model.ReportHistory
+	if err := cur.All(ctx, &histories); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if int64(len(histories)) > limit {
+		histories = histories[:limit]
+		last := histories[len(histories)-1]
+		nextCursor = encodeCursor(last.Timestamp, last.ID)
+	}
+
+	histories, err = r.hydrate(ctx, histories)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return histories, nextCursor, nil
+}
+
+func (r *reportHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "report_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "report_id", Value: 1}, {Key: "version", Value: 1}}},
+		// mỗi seq chỉ cấp một lần trong chuỗi history của report, history cũ chưa có mode không bị ràng buộc
+		{
+			Keys: bson.D{{Key: "report_id", Value: 1}, {Key: "seq", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"mode": bson.M{"$exists": true}}),
+		},
+		{Keys: bson.D{{Key: "student_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "report.student_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "classroom_id", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "editor_id", Value: 1}, {Key: "editor_role", Value: 1}, {Key: "timestamp", Value: -1}}},
+		{Keys: bson.D{{Key: "type", Value: 1}, {Key: "timestamp", Value: -1}}},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
 }
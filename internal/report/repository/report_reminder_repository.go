@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReportReminderRepository interface {
+	Claim(ctx context.Context, organizationID, teacherID string, now time.Time, cooldown time.Duration) (bool, error)
+	Release(ctx context.Context, organizationID, teacherID string, claimedAt time.Time) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type reportReminderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportReminderRepository(collection *mongo.Collection) ReportReminderRepository {
+	return &reportReminderRepository{collection: collection}
+}
+
+func reminderLogID(organizationID, teacherID string) string {
+	return organizationID + "|" + teacherID
+}
+
+// Claim giữ lượt gửi digest cho teacher nếu đã qua cooldown. Upsert nguyên tử nên
+// nhiều replica / lần restart cũng chỉ một nơi claim được, trả về false nếu chưa đến lượt.
+func (r *reportReminderRepository) Claim(ctx context.Context, organizationID, teacherID string, now time.Time, cooldown time.Duration) (bool, error) {
+	now = now.Truncate(time.Millisecond) // mongo lưu đến ms, Release so khớp đúng mốc này
+	filter := bson.M{
+		"_id":          reminderLogID(organizationID, teacherID),
+		"last_sent_at": bson.M{"$lte": now.Add(-cooldown)},
+	}
+	update := bson.M{"$set": bson.M{
+		"organization_id": organizationID,
+		"teacher_id":      teacherID,
+		"last_sent_at":    now,
+	}}
+
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err == nil {
+		return true, nil
+	}
+	// đã có bản ghi còn trong cooldown: filter không khớp nên upsert đụng _id
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	return false, fmt.Errorf("claim report reminder failed: %w", err)
+}
+
+// Release trả lại lượt đã claim khi gửi lỗi để lượt quét sau gửi lại
+func (r *reportReminderRepository) Release(ctx context.Context, organizationID, teacherID string, claimedAt time.Time) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{
+		"_id":          reminderLogID(organizationID, teacherID),
+		"last_sent_at": claimedAt.Truncate(time.Millisecond),
+	})
+	if err != nil {
+		return fmt.Errorf("release report reminder failed: %w", err)
+	}
+	return nil
+}
+
+func (r *reportReminderRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "teacher_id", Value: 1}}},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
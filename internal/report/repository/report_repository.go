@@ -5,7 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"report-service/helper"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/diff"
 	"report-service/internal/report/model"
+	"report-service/internal/report/workflow"
+	"report-service/pkg/constants"
 	"strings"
 	"time"
 
@@ -24,20 +28,55 @@ type ReportRepository interface {
 	GetByStudentTopicTermAndLanguage(ctx context.Context, studentID, topicID, termID, language string) (*model.Report, error)
 	GetByStudentTopicTermLanguageAndEditor(ctx context.Context, studentID, topicID, termID, language, editorID string) (*model.Report, error)
 	GetAllByEditorID(ctx context.Context, editorID string) ([]*model.Report, error)
-	CreateOrUpdateStudentView4App(ctx context.Context, report *model.Report) error
-	CreateOrUpdateStudentView4Web(ctx context.Context, report *model.Report) error
-	CreateOrUpdateClassroomView4Web(ctx context.Context, report *model.Report) error
+	CreateOrUpdateStudentView4App(ctx context.Context, report *model.Report) ([]model.SectionTransition, error)
+	CreateOrUpdateStudentView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error)
+	CreateOrUpdateClassroomView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error)
 	GetTopicsByTermTopicLanguage(ctx context.Context, termID, topicID, language string) ([]*model.Report, error)
 	ApplyTopicPlanTemplate(ctx context.Context, report *model.Report) error
 	GetByEditorIDAndStudentIDAndTermID(ctx context.Context, editorID, studentID, termID string) ([]*model.Report, error)
+	GetByTermAndTopic(ctx context.Context, termID, topicID string) ([]*model.Report, error)
+	GetPendingByOrganizationAndTerm(ctx context.Context, organizationID, termID string, statuses, missingSections []string) ([]*model.Report, error)
+	SendBackSections(ctx context.Context, report *model.Report, rejections []model.SectionRejection) ([]model.SectionTransition, error)
+	ResolveRejections(ctx context.Context, reportID primitive.ObjectID, sections []string) error
+	RestoreSnapshot(ctx context.Context, report *model.Report) ([]model.SectionTransition, error)
+	AcquireEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock, ttl time.Duration) (*model.EditingLock, error)
+	RenewEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock, ttl time.Duration) (*model.EditingLock, error)
+	ReleaseEditingLock(ctx context.Context, reportID primitive.ObjectID, holder model.EditingLock) error
+	GetChangedByEditor(ctx context.Context, editorID, syncToken string, limit int64) ([]*model.Report, string, bool, error)
+	OrganizationsByStudent(ctx context.Context) (map[string]string, error)
+	BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+var (
+	ErrVersionConflict = errors.New("report version conflict")
+	ErrUnknownSection  = errors.New("section not found in report")
+)
+
+// VersionConflictError: client gửi version cũ, kèm version hiện tại trên server để client merge.
+// Conflicts có giá trị khi server đã thử merge nhưng cùng field bị sửa ở cả 2 phía.
+type VersionConflictError struct {
+	ReportID       primitive.ObjectID
+	CurrentVersion int64
+	Conflicts      []diff.MergeConflict
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%v: current version is %d", ErrVersionConflict, e.CurrentVersion)
+}
+
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
 }
 
 type reportRepository struct {
 	collection *mongo.Collection
+	// counters giữ change_seq toàn cục, mỗi lần ghi report cấp một số mới để app sync theo thứ tự ghi
+	counters *mongo.Collection
 }
 
-func NewReportRepository(collection *mongo.Collection) ReportRepository {
-	return &reportRepository{collection}
+func NewReportRepository(collection, counters *mongo.Collection) ReportRepository {
+	return &reportRepository{collection: collection, counters: counters}
 }
 
 func (r *reportRepository) Create(ctx context.Context, report *model.Report) (*model.Report, error) {
@@ -45,8 +84,17 @@ func (r *reportRepository) Create(ctx context.Context, report *model.Report) (*m
 	report.ID = primitive.NewObjectID()
 	report.CreatedAt = now
 	report.UpdatedAt = now
+	if report.OrganizationID == "" {
+		report.OrganizationID = organizationFromContext(ctx)
+	}
 
-	_, err := r.collection.InsertOne(ctx, report)
+	seq, err := r.nextChangeSeq(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.ChangeSeq = seq
+
+	_, err = r.collection.InsertOne(ctx, report)
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +167,7 @@ func (r *reportRepository) CreateOrUpdate(ctx context.Context, report *model.Rep
 			"report_data": report.ReportData,
 			"updated_at":  report.UpdatedAt,
 		},
+		"$inc": bson.M{"version": 1},
 		"$setOnInsert": bson.M{
 			"_id":        report.ID,
 			"created_at": report.CreatedAt,
@@ -127,6 +176,9 @@ func (r *reportRepository) CreateOrUpdate(ctx context.Context, report *model.Rep
 
 	// upsert = true → nếu chưa có thì insert, có rồi thì update
 	opts := options.Update().SetUpsert(true)
+	if err := r.stampChange(ctx, update); err != nil {
+		return err
+	}
 	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
 	return err
 }
@@ -188,7 +240,13 @@ func (r *reportRepository) GetAllByEditorID(ctx context.Context, editorID string
 	return reports, nil
 }
 
-func (r *reportRepository) CreateOrUpdateStudentView4App(ctx context.Context, report *model.Report) error {
+func (r *reportRepository) CreateOrUpdateStudentView4App(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
+	return retryStaleWrite(report, func() ([]model.SectionTransition, error) {
+		return r.createOrUpdateStudentView4App(ctx, report)
+	})
+}
+
+func (r *reportRepository) createOrUpdateStudentView4App(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
 	filter := bson.M{
 		"student_id": report.StudentID,
 		"topic_id":   report.TopicID,
@@ -196,14 +254,28 @@ func (r *reportRepository) CreateOrUpdateStudentView4App(ctx context.Context, re
 		"language":   report.Language,
 	}
 
+	current, err := r.findCurrent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("get current report failed: %w", err)
+	}
+	if err := checkEditingLock(current, report.Writer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, report.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"status":     report.Status,
 			"editor_id":  report.EditorID,
 			"updated_at": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
+	var transitions []model.SectionTransition
+
 	// merge report_data
 	for section, data := range report.ReportData {
 		if section == "goal" || section == "title" || section == "curriculum_area" {
@@ -218,6 +290,16 @@ func (r *reportRepository) CreateOrUpdateStudentView4App(ctx context.Context, re
 			if strings.HasPrefix(k, "manager_") {
 				continue
 			}
+			if k == "status" {
+				transition, status, err := checkSectionStatus(constants.ReportHistoryRoleTeacher, current, section, v)
+				if err != nil {
+					return nil, err
+				}
+				if transition != nil {
+					transitions = append(transitions, *transition)
+				}
+				v = string(status)
+			}
 			update["$set"].(bson.M)[fmt.Sprintf("report_data.%s.%s", section, k)] = v
 		}
 	}
@@ -225,19 +307,35 @@ func (r *reportRepository) CreateOrUpdateStudentView4App(ctx context.Context, re
 	// chỉ khi insert mới set các field này
 	update["$setOnInsert"] = bson.M{
 		"created_at": time.Now(),
-		"editing":    true,
 	}
 
-	opts := options.Update().SetUpsert(true)
-	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	// report đã tồn tại thì chỉ update khi version vẫn là bản đã đọc để check status (không upsert để tránh tạo bản trùng)
+	if current != nil {
+		filter = withVersion(bson.M{"_id": current.ID}, current.Version)
+	}
+
+	if err := r.stampChange(ctx, update); err != nil {
+		return nil, err
+	}
+	saved, err := r.updateAndGet(ctx, filter, update, current == nil)
 	if err != nil {
-		return fmt.Errorf("create or update report failed: %w", err)
+		return nil, fmt.Errorf("create or update report failed: %w", err)
+	}
+	if saved == nil {
+		return nil, r.conflictError(ctx, current.ID)
 	}
 
-	return nil
+	setSaved(report, saved)
+	return transitions, nil
 }
 
-func (r *reportRepository) CreateOrUpdateStudentView4Web(ctx context.Context, report *model.Report) error {
+func (r *reportRepository) CreateOrUpdateStudentView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
+	return retryStaleWrite(report, func() ([]model.SectionTransition, error) {
+		return r.createOrUpdateStudentView4Web(ctx, report)
+	})
+}
+
+func (r *reportRepository) createOrUpdateStudentView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
 	filter := bson.M{
 		"student_id": report.StudentID,
 		"topic_id":   report.TopicID,
@@ -245,14 +343,30 @@ func (r *reportRepository) CreateOrUpdateStudentView4Web(ctx context.Context, re
 		"language":   report.Language,
 	}
 
+	current, err := r.findCurrent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("get current report failed: %w", err)
+	}
+	if current == nil {
+		return nil, errors.New("report not found")
+	}
+	if err := checkEditingLock(current, report.Writer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, report.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
 	update := bson.M{
+		"$inc": bson.M{"version": 1},
 		"$set": bson.M{
 			"status":     report.Status,
-			"editing":    report.Editing,
 			"updated_at": time.Now(),
 		},
 	}
 
+	var transitions []model.SectionTransition
+
 	for section, data := range report.ReportData {
 		subData, ok := data.(map[string]interface{})
 		if !ok {
@@ -260,25 +374,47 @@ func (r *reportRepository) CreateOrUpdateStudentView4Web(ctx context.Context, re
 		}
 
 		for k, v := range subData {
-			if strings.HasPrefix(k, "manager_") || k == "status" || k == "color" || k == "teacher_report" {
+			if managerWritable(k) {
+				if k == "status" {
+					transition, status, err := checkSectionStatus(constants.ReportHistoryRoleManager, current, section, v)
+					if err != nil {
+						return nil, err
+					}
+					if transition != nil {
+						transitions = append(transitions, *transition)
+					}
+					v = string(status)
+				}
 				update["$set"].(bson.M)[fmt.Sprintf("report_data.%s.%s", section, k)] = v
 			}
 		}
 	}
 
-	opts := options.Update().SetUpsert(false)
-	res, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	// chỉ update khi version vẫn là bản đã đọc để check status
+	filter = withVersion(bson.M{"_id": current.ID}, current.Version)
+
+	if err := r.stampChange(ctx, update); err != nil {
+		return nil, err
+	}
+	saved, err := r.updateAndGet(ctx, filter, update, false)
 	if err != nil {
-		return fmt.Errorf("update report (web) failed: %w", err)
+		return nil, fmt.Errorf("update report (web) failed: %w", err)
 	}
-	if res.MatchedCount == 0 {
-		return errors.New("report not found")
+	if saved == nil {
+		return nil, r.conflictError(ctx, current.ID)
 	}
 
-	return nil
+	setSaved(report, saved)
+	return transitions, nil
 }
 
-func (r *reportRepository) CreateOrUpdateClassroomView4Web(ctx context.Context, report *model.Report) error {
+func (r *reportRepository) CreateOrUpdateClassroomView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
+	return retryStaleWrite(report, func() ([]model.SectionTransition, error) {
+		return r.createOrUpdateClassroomView4Web(ctx, report)
+	})
+}
+
+func (r *reportRepository) createOrUpdateClassroomView4Web(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
 	filter := bson.M{
 		"student_id": report.StudentID,
 		"topic_id":   report.TopicID,
@@ -286,13 +422,30 @@ func (r *reportRepository) CreateOrUpdateClassroomView4Web(ctx context.Context,
 		"language":   report.Language,
 	}
 
+	current, err := r.findCurrent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("get current report failed: %w", err)
+	}
+	if current == nil {
+		return nil, errors.New("report not found")
+	}
+	if err := checkEditingLock(current, report.Writer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, report.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
 	update := bson.M{
+		"$inc": bson.M{"version": 1},
 		"$set": bson.M{
 			"status":     report.Status,
 			"updated_at": time.Now(),
 		},
 	}
 
+	var transitions []model.SectionTransition
+
 	for section, data := range report.ReportData {
 		subData, ok := data.(map[string]interface{})
 		if !ok {
@@ -307,24 +460,378 @@ func (r *reportRepository) CreateOrUpdateClassroomView4Web(ctx context.Context,
 		}
 
 		for k, v := range subData {
-			if strings.HasPrefix(k, "manager_") || k == "status" || k == "color" || k == "teacher_report" {
+			if managerWritable(k) {
+				if k == "status" {
+					transition, status, err := checkSectionStatus(constants.ReportHistoryRoleManager, current, section, v)
+					if err != nil {
+						return nil, err
+					}
+					if transition != nil {
+						transitions = append(transitions, *transition)
+					}
+					v = string(status)
+				}
 				update["$set"].(bson.M)[fmt.Sprintf("report_data.%s.%s", section, k)] = v
 			}
 		}
 	}
 
-	opts := options.Update().SetUpsert(false)
-	res, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	// chỉ update khi version vẫn là bản đã đọc để check status
+	filter = withVersion(bson.M{"_id": current.ID}, current.Version)
+
+	if err := r.stampChange(ctx, update); err != nil {
+		return nil, err
+	}
+	saved, err := r.updateAndGet(ctx, filter, update, false)
 	if err != nil {
-		return fmt.Errorf("update report (web) failed: %w", err)
+		return nil, fmt.Errorf("update report (web) failed: %w", err)
 	}
-	if res.MatchedCount == 0 {
-		return errors.New("report not found")
+	if saved == nil {
+		return nil, r.conflictError(ctx, current.ID)
+	}
+
+	setSaved(report, saved)
+	return transitions, nil
+}
+
+func (r *reportRepository) SendBackSections(ctx context.Context, report *model.Report, rejections []model.SectionRejection) ([]model.SectionTransition, error) {
+	return retryStaleWrite(report, func() ([]model.SectionTransition, error) {
+		return r.sendBackSections(ctx, report, rejections)
+	})
+}
+
+func (r *reportRepository) sendBackSections(ctx context.Context, report *model.Report, rejections []model.SectionRejection) ([]model.SectionTransition, error) {
+	filter := bson.M{
+		"student_id": report.StudentID,
+		"topic_id":   report.TopicID,
+		"term_id":    report.TermID,
+		"language":   report.Language,
+	}
+
+	current, err := r.findCurrent(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("get current report failed: %w", err)
+	}
+	if current == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if err := checkEditingLock(current, report.Writer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, report.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+		"$push": bson.M{
+			"rejections": bson.M{"$each": rejections},
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	var transitions []model.SectionTransition
+	for _, rejection := range rejections {
+		if _, ok := current.ReportData[rejection.Section]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSection, rejection.Section)
+		}
+
+		transition, status, err := checkSectionStatus(constants.ReportHistoryRoleManager, current, rejection.Section, string(constants.SectionStatusTeacher))
+		if err != nil {
+			return nil, err
+		}
+		if transition == nil {
+			return nil, &workflow.TransitionError{Section: rejection.Section, From: status, To: string(status), Role: constants.ReportHistoryRoleManager, Err: workflow.ErrInvalidTransition}
+		}
+		transitions = append(transitions, *transition)
+		update["$set"].(bson.M)[fmt.Sprintf("report_data.%s.status", rejection.Section)] = string(status)
 	}
 
+	if err := r.stampChange(ctx, update); err != nil {
+		return nil, err
+	}
+	// chỉ update khi version vẫn là bản đã đọc để check status
+	saved, err := r.updateAndGet(ctx, withVersion(bson.M{"_id": current.ID}, current.Version), update, false)
+	if err != nil {
+		return nil, fmt.Errorf("send back report failed: %w", err)
+	}
+	if saved == nil {
+		return nil, r.conflictError(ctx, current.ID)
+	}
+
+	setSaved(report, saved)
+	return transitions, nil
+}
+
+// ResolveRejections đánh dấu các lần trả về của section đã được teacher xử lý
+func (r *reportRepository) ResolveRejections(ctx context.Context, reportID primitive.ObjectID, sections []string) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	filter := bson.M{
+		"_id":                reportID,
+		"rejections.section": bson.M{"$in": sections},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"rejections.$[r].resolved_at": time.Now(),
+		},
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{
+			bson.M{"r.section": bson.M{"$in": sections}, "r.resolved_at": nil},
+		},
+	})
+
+	if err := r.stampChange(ctx, update); err != nil {
+		return err
+	}
+	_, err := r.collection.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		return fmt.Errorf("resolve rejections failed: %w", err)
+	}
 	return nil
 }
 
+// RestoreSnapshot ghi đè status và report_data của report.ID bằng dữ liệu snapshot,
+// các section đổi status phải hợp lệ theo workflow của manager
+func (r *reportRepository) RestoreSnapshot(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
+	return retryStaleWrite(report, func() ([]model.SectionTransition, error) {
+		return r.restoreSnapshot(ctx, report)
+	})
+}
+
+func (r *reportRepository) restoreSnapshot(ctx context.Context, report *model.Report) ([]model.SectionTransition, error) {
+	current, err := r.findCurrent(ctx, bson.M{"_id": report.ID})
+	if err != nil {
+		return nil, fmt.Errorf("get current report failed: %w", err)
+	}
+	if current == nil {
+		return nil, mongo.ErrNoDocuments
+	}
+	if err := checkEditingLock(current, report.Writer); err != nil {
+		return nil, err
+	}
+	if err := checkVersion(current, report.ExpectedVersion); err != nil {
+		return nil, err
+	}
+
+	reportData := report.ReportData
+	if reportData == nil {
+		reportData = bson.M{}
+	}
+
+	transitions := workflow.StatusChanges(constants.ReportHistoryRoleManager, current.ReportData, reportData)
+	for _, t := range transitions {
+		if _, _, err := workflow.CheckTransition(constants.ReportHistoryRoleManager, t.Section, t.From, t.To); err != nil {
+			return nil, err
+		}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":      report.Status,
+			"report_data": reportData,
+			"updated_at":  time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	if err := r.stampChange(ctx, update); err != nil {
+		return nil, err
+	}
+	saved, err := r.updateAndGet(ctx, withVersion(bson.M{"_id": current.ID}, current.Version), update, false)
+	if err != nil {
+		return nil, fmt.Errorf("restore report failed: %w", err)
+	}
+	if saved == nil {
+		return nil, r.conflictError(ctx, current.ID)
+	}
+
+	setSaved(report, saved)
+	return transitions, nil
+}
+
+// updateAndGet ghi update và trả về document ngay sau lần ghi này, nil nếu filter không khớp
+func (r *reportRepository) updateAndGet(ctx context.Context, filter, update bson.M, upsert bool) (*model.Report, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(upsert).SetReturnDocument(options.After)
+
+	var saved model.Report
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&saved)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// setSaved gắn kết quả lần ghi vào report để usecase trả version và lưu history đúng bản đã ghi
+func setSaved(report, saved *model.Report) {
+	report.ID = saved.ID
+	report.Version = saved.Version
+	report.Saved = saved
+}
+
+// reportChangeSeqKey: _id của document đếm change_seq trong collection counters
+const reportChangeSeqKey = "report_change_seq"
+
+// nextChangeSeq cấp change_seq tiếp theo ($inc nguyên tử nên không trùng giữa các replica)
+func (r *reportRepository) nextChangeSeq(ctx context.Context) (int64, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := r.counters.FindOneAndUpdate(ctx,
+		bson.M{"_id": reportChangeSeqKey},
+		bson.M{"$inc": bson.M{"seq": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("allocate report change seq failed: %w", err)
+	}
+	return counter.Seq, nil
+}
+
+// stampChange gắn change_seq mới vào update để app sync thấy thay đổi này,
+// kèm organization của người ghi để job nhắc deadline lọc được theo organization
+func (r *reportRepository) stampChange(ctx context.Context, update bson.M) error {
+	seq, err := r.nextChangeSeq(ctx)
+	if err != nil {
+		return err
+	}
+	set, ok := update["$set"].(bson.M)
+	if !ok {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["change_seq"] = seq
+	if organizationID := organizationFromContext(ctx); organizationID != "" {
+		set["organization_id"] = organizationID
+	}
+	return nil
+}
+
+// organizationFromContext: organization của user đang gọi, rỗng khi không có user (job nền)
+func organizationFromContext(ctx context.Context) string {
+	currentUser, ok := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if !ok || currentUser == nil {
+		return ""
+	}
+	if currentUser.OrganizationAdmin != nil {
+		return currentUser.OrganizationAdmin.ID
+	}
+	return currentUser.OrganizationIdActive
+}
+
+// findCurrent lấy report hiện tại theo filter, trả về nil nếu chưa có
+func (r *reportRepository) findCurrent(ctx context.Context, filter bson.M) (*model.Report, error) {
+	var report model.Report
+	err := r.collection.FindOne(ctx, filter).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// checkVersion so version client gửi với version hiện tại, expected nil = bỏ qua kiểm tra
+func checkVersion(current *model.Report, expected *int64) error {
+	if expected == nil {
+		return nil
+	}
+
+	var currentVersion int64
+	if current != nil {
+		currentVersion = current.Version
+	}
+	if currentVersion != *expected {
+		conflict := &VersionConflictError{CurrentVersion: currentVersion}
+		if current != nil {
+			conflict.ReportID = current.ID
+		}
+		return conflict
+	}
+	return nil
+}
+
+// workflowFields: field chung của mọi section (xem schema.sectionFields), field ngoài danh sách
+// là giá trị của section tuỳ biến (rating, mục checklist) đã qua validate schema, manager được ghi
+var workflowFields = map[string]bool{
+	"color":              true,
+	"content":            true,
+	"status":             true,
+	"teacher_report":     true,
+	"manager_comment":    true,
+	"manager_note":       true,
+	"note_for_teacher":   true,
+	"updated_at":         true,
+	"manager_updated_at": true,
+	"latest_update_time": true,
+}
+
+// managerWritable: field manager được ghi khi lưu từ web (student view và classroom view dùng chung)
+func managerWritable(field string) bool {
+	return strings.HasPrefix(field, "manager_") || field == "status" || field == "color" || field == "teacher_report" || !workflowFields[field]
+}
+
+// maxStaleWriteAttempts: số lần đọc lại + ghi khi report bị người khác ghi chen giữa lúc check và update
+const maxStaleWriteAttempts = 3
+
+// retryStaleWrite: client không gửi expected_version thì tự đọc lại và check lại status khi bị ghi chen,
+// client có gửi version thì trả conflict ngay để client tự merge
+func retryStaleWrite(report *model.Report, write func() ([]model.SectionTransition, error)) ([]model.SectionTransition, error) {
+	for attempt := 1; ; attempt++ {
+		transitions, err := write()
+		var conflict *VersionConflictError
+		if report.ExpectedVersion == nil && errors.As(err, &conflict) && attempt < maxStaleWriteAttempts {
+			continue
+		}
+		return transitions, err
+	}
+}
+
+// withVersion thêm điều kiện version vào filter, report cũ chưa có field version được coi là version 0
+func withVersion(filter bson.M, expected int64) bson.M {
+	if expected == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["version"] = expected
+	}
+	return filter
+}
+
+// conflictError đọc lại version mới nhất khi update không match do report vừa bị ghi bởi người khác
+func (r *reportRepository) conflictError(ctx context.Context, reportID primitive.ObjectID) error {
+	latest, err := r.findCurrent(ctx, bson.M{"_id": reportID})
+	if err != nil {
+		return fmt.Errorf("get current report failed: %w", err)
+	}
+	if latest == nil {
+		return errors.New("report not found")
+	}
+	return &VersionConflictError{ReportID: latest.ID, CurrentVersion: latest.Version}
+}
+
+// checkSectionStatus validate status mới của section theo workflow
+func checkSectionStatus(role constants.ReportHistoryRole, current *model.Report, section string, value interface{}) (*model.SectionTransition, constants.SectionStatus, error) {
+	next, _ := value.(string)
+
+	currentStatus := ""
+	if current != nil {
+		currentStatus, _ = helper.ToBsonM(current.ReportData[section])["status"].(string)
+	}
+
+	return workflow.CheckTransition(role, section, currentStatus, next)
+}
+
 func (r *reportRepository) GetTopicsByTermTopicLanguage(ctx context.Context, termID, topicID, language string) ([]*model.Report, error) {
 	filter := bson.M{
 		"term_id":  termID,
@@ -364,8 +871,12 @@ func (r *reportRepository) ApplyTopicPlanTemplate(ctx context.Context, report *m
 
 	// cập nhật updated_at
 	update["$set"].(bson.M)["updated_at"] = time.Now()
+	update["$inc"] = bson.M{"version": 1}
 
 	opts := options.Update().SetUpsert(false)
+	if err := r.stampChange(ctx, update); err != nil {
+		return err
+	}
 	res, err := r.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
 		return fmt.Errorf("apply template failed: %w", err)
@@ -393,3 +904,169 @@ func (r *reportRepository) GetByEditorIDAndStudentIDAndTermID(ctx context.Contex
 	}
 	return reports, nil
 }
+
+// GetByTermAndTopic lấy report của term, topicID rỗng = mọi topic
+func (r *reportRepository) GetByTermAndTopic(ctx context.Context, termID, topicID string) ([]*model.Report, error) {
+	filter := bson.M{"term_id": termID}
+	if topicID != "" {
+		filter["topic_id"] = topicID
+	}
+
+	var reports []*model.Report
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetPendingByOrganizationAndTerm lấy report đã có editor của organization trong term mà còn section
+// có status thuộc statuses (so sánh không phân biệt hoa thường) hoặc chưa có một trong missingSections
+func (r *reportRepository) GetPendingByOrganizationAndTerm(ctx context.Context, organizationID, termID string, statuses, missingSections []string) ([]*model.Report, error) {
+	normalized := make([]string, 0, len(statuses))
+	for _, status := range statuses {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(status)))
+	}
+
+	// status không phải string thì bỏ qua, giống workflow.ParseStatus
+	sectionStatus := bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$type": "$$section.v.status"}, "string"}},
+		bson.M{"$toLower": bson.M{"$trim": bson.M{"input": "$$section.v.status"}}},
+		nil,
+	}}
+	pending := bson.A{bson.M{"$expr": bson.M{"$anyElementTrue": bson.A{bson.M{"$map": bson.M{
+		"input": bson.M{"$objectToArray": bson.M{"$ifNull": bson.A{"$report_data", bson.M{}}}},
+		"as":    "section",
+		"in":    bson.M{"$in": bson.A{sectionStatus, normalized}},
+	}}}}}}
+	for _, section := range missingSections {
+		pending = append(pending, bson.M{"report_data." + section: bson.M{"$exists": false}})
+	}
+
+	filter := bson.M{
+		"organization_id": organizationID,
+		"term_id":         termID,
+		"editor_id":       bson.M{"$nin": bson.A{"", nil}},
+		"$or":             pending,
+	}
+
+	var reports []*model.Report
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetChangedByEditor lấy report của editor thay đổi sau sync token (change_seq, _id), cũ nhất trước.
+// Token cũ theo updated_at được coi như sync lại từ đầu.
+func (r *reportRepository) GetChangedByEditor(ctx context.Context, editorID, syncToken string, limit int64) ([]*model.Report, string, bool, error) {
+	filter := bson.M{"editor_id": editorID}
+
+	if syncToken != "" {
+		seq, id, ok, err := decodeSyncToken(syncToken)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if ok {
+			// report cũ chưa có change_seq được coi là 0
+			same := bson.M{"change_seq": seq}
+			if seq == 0 {
+				same = bson.M{"change_seq": bson.M{"$in": bson.A{0, nil}}}
+			}
+			same["_id"] = bson.M{"$gt": id}
+			filter["$or"] = bson.A{
+				bson.M{"change_seq": bson.M{"$gt": seq}},
+				same,
+			}
+		}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "change_seq", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit + 1)
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []*model.Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, "", false, err
+	}
+
+	hasMore := int64(len(reports)) > limit
+	if hasMore {
+		reports = reports[:limit]
+	}
+
+	nextToken := syncToken
+	if len(reports) > 0 {
+		last := reports[len(reports)-1]
+		nextToken = encodeSyncToken(last.ChangeSeq, last.ID)
+	}
+
+	return reports, nextToken, hasMore, nil
+}
+
+// OrganizationsByStudent lấy organization của từng học sinh từ các report đã có organization_id,
+// học sinh chuyển organization thì lấy organization của report ghi gần nhất
+func (r *reportRepository) OrganizationsByStudent(ctx context.Context) (map[string]string, error) {
+	cur, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"organization_id": bson.M{"$nin": bson.A{nil, ""}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$student_id", "organization_id": bson.M{"$last": "$organization_id"}}}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get report organizations failed: %w", err)
+	}
+
+	var rows []struct {
+		StudentID      string `bson:"_id"`
+		OrganizationID string `bson:"organization_id"`
+	}
+	if err := cur.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	organizations := make(map[string]string, len(rows))
+	for _, row := range rows {
+		organizations[row.StudentID] = row.OrganizationID
+	}
+	return organizations, nil
+}
+
+// BackfillOrganization gắn organization_id cho report ghi trước khi có field này theo học sinh,
+// để job nhắc deadline thấy được report cũ. Không đổi version / change_seq vì nội dung report không đổi.
+func (r *reportRepository) BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error) {
+	var updated int64
+	for studentID, organizationID := range organizationByStudent {
+		filter := bson.M{"$and": bson.A{missingOrganization, bson.M{"student_id": studentID}}}
+		res, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"organization_id": organizationID}})
+		if err != nil {
+			return updated, fmt.Errorf("backfill report organization of student %s failed: %w", studentID, err)
+		}
+		updated += res.ModifiedCount
+	}
+	return updated, nil
+}
+
+func (r *reportRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "editor_id", Value: 1}, {Key: "updated_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "editor_id", Value: 1}, {Key: "change_seq", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "term_id", Value: 1}, {Key: "topic_id", Value: 1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "term_id", Value: 1}}},
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReportSchemaRepository interface {
+	GetByOrganization(ctx context.Context, organizationID string) (*model.ReportSchema, error)
+	Upsert(ctx context.Context, schema *model.ReportSchema) error
+}
+
+type reportSchemaRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportSchemaRepository(collection *mongo.Collection) ReportSchemaRepository {
+	return &reportSchemaRepository{collection}
+}
+
+// GetByOrganization trả về phần mở rộng schema của organization, nil nếu chưa có
+func (r *reportSchemaRepository) GetByOrganization(ctx context.Context, organizationID string) (*model.ReportSchema, error) {
+	var schema model.ReportSchema
+	err := r.collection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&schema)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (r *reportSchemaRepository) Upsert(ctx context.Context, schema *model.ReportSchema) error {
+	schema.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"sections":   schema.Sections,
+			"updated_by": schema.UpdatedBy,
+			"updated_at": schema.UpdatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(ctx, bson.M{"organization_id": schema.OrganizationID}, update, opts)
+	return err
+}
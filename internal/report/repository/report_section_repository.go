@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrSectionNotFound  = errors.New("report section not found")
+	ErrSectionKeyExists = errors.New("report section key already exists")
+)
+
+type ReportSectionRepository interface {
+	GetByOrganization(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error)
+	GetRetiredByOrganization(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error)
+	GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportSectionDefinition, error)
+	Create(ctx context.Context, section *model.ReportSectionDefinition) error
+	Update(ctx context.Context, section *model.ReportSectionDefinition) error
+	Delete(ctx context.Context, organizationID string, id primitive.ObjectID) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+type reportSectionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportSectionRepository(collection *mongo.Collection) ReportSectionRepository {
+	return &reportSectionRepository{collection}
+}
+
+// GetByOrganization trả về các section tuỳ biến đang dùng theo thứ tự hiển thị
+func (r *reportSectionRepository) GetByOrganization(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error) {
+	return r.find(ctx, bson.M{"organization_id": organizationID, "retired_at": nil})
+}
+
+// GetRetiredByOrganization trả về các section đã xoá, report cũ vẫn còn dữ liệu của chúng
+func (r *reportSectionRepository) GetRetiredByOrganization(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error) {
+	return r.find(ctx, bson.M{"organization_id": organizationID, "retired_at": bson.M{"$ne": nil}})
+}
+
+func (r *reportSectionRepository) find(ctx context.Context, filter bson.M) ([]*model.ReportSectionDefinition, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "order", Value: 1}, {Key: "key", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []*model.ReportSectionDefinition
+	if err := cursor.All(ctx, &sections); err != nil {
+		return nil, err
+	}
+	return sections, nil
+}
+
+func (r *reportSectionRepository) GetByID(ctx context.Context, organizationID string, id primitive.ObjectID) (*model.ReportSectionDefinition, error) {
+	var section model.ReportSectionDefinition
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "organization_id": organizationID, "retired_at": nil}).Decode(&section)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrSectionNotFound
+		}
+		return nil, err
+	}
+	return &section, nil
+}
+
+func (r *reportSectionRepository) Create(ctx context.Context, section *model.ReportSectionDefinition) error {
+	now := time.Now()
+	section.ID = primitive.NewObjectID()
+	section.CreatedAt = now
+	section.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, section)
+	if mongo.IsDuplicateKeyError(err) {
+		return r.revive(ctx, section)
+	}
+	return err
+}
+
+// revive: tạo lại section trùng key với section đã xoá thì dùng lại bản ghi cũ,
+// dữ liệu trong report_data vẫn nằm dưới key đó
+func (r *reportSectionRepository) revive(ctx context.Context, section *model.ReportSectionDefinition) error {
+	var revived model.ReportSectionDefinition
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"organization_id": section.OrganizationID, "key": section.Key, "retired_at": bson.M{"$ne": nil}},
+		bson.M{
+			"$set": bson.M{
+				"title":          section.Title,
+				"type":           section.Type,
+				"rating":         section.Rating,
+				"items":          section.Items,
+				"order":          section.Order,
+				"track_progress": section.TrackProgress,
+				"updated_by":     section.UpdatedBy,
+				"updated_at":     section.UpdatedAt,
+			},
+			"$unset": bson.M{"retired_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&revived)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return ErrSectionKeyExists
+		}
+		return err
+	}
+
+	section.ID = revived.ID
+	section.CreatedBy = revived.CreatedBy
+	section.CreatedAt = revived.CreatedAt
+	return nil
+}
+
+// Update không cho đổi key: dữ liệu cũ trong report_data vẫn nằm dưới key ban đầu
+func (r *reportSectionRepository) Update(ctx context.Context, section *model.ReportSectionDefinition) error {
+	section.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"title":          section.Title,
+			"type":           section.Type,
+			"rating":         section.Rating,
+			"items":          section.Items,
+			"order":          section.Order,
+			"track_progress": section.TrackProgress,
+			"updated_by":     section.UpdatedBy,
+			"updated_at":     section.UpdatedAt,
+		},
+	}
+
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": section.ID, "organization_id": section.OrganizationID, "retired_at": nil}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrSectionNotFound
+	}
+	return nil
+}
+
+// Delete chỉ đánh dấu retired, giữ định nghĩa để report cũ còn dữ liệu của section vẫn lưu được
+func (r *reportSectionRepository) Delete(ctx context.Context, organizationID string, id primitive.ObjectID) error {
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"retired_at": now, "updated_at": now}}
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "organization_id": organizationID, "retired_at": nil}, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrSectionNotFound
+	}
+	return nil
+}
+
+func (r *reportSectionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
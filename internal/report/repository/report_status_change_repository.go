@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"report-service/internal/report/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReportStatusChangeRepository interface {
+	CreateMany(ctx context.Context, changes []*model.ReportStatusChange) error
+	GetByTerm(ctx context.Context, organizationID, termID, language string) ([]*model.ReportStatusChange, error)
+	BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type reportStatusChangeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportStatusChangeRepository(collection *mongo.Collection) ReportStatusChangeRepository {
+	return &reportStatusChangeRepository{collection}
+}
+
+func (r *reportStatusChangeRepository) CreateMany(ctx context.Context, changes []*model.ReportStatusChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(changes))
+	for _, c := range changes {
+		if c.ID.IsZero() {
+			c.ID = primitive.NewObjectID()
+		}
+		docs = append(docs, c)
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// GetByTerm trả về các lần đổi status của organization trong term theo thứ tự thời gian, language rỗng = mọi ngôn ngữ
+func (r *reportStatusChangeRepository) GetByTerm(ctx context.Context, organizationID, termID, language string) ([]*model.ReportStatusChange, error) {
+	filter := bson.M{"organization_id": organizationID, "term_id": termID}
+	if language != "" {
+		filter["language"] = language
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "changed_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*model.ReportStatusChange
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// BackfillOrganization gắn organization_id cho bản ghi cũ theo học sinh, trả về số bản ghi đã cập nhật
+func (r *reportStatusChangeRepository) BackfillOrganization(ctx context.Context, organizationByStudent map[string]string) (int64, error) {
+	var updated int64
+	for studentID, organizationID := range organizationByStudent {
+		filter := bson.M{"$and": bson.A{missingOrganization, bson.M{"student_id": studentID}}}
+		res, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"organization_id": organizationID}})
+		if err != nil {
+			return updated, fmt.Errorf("backfill status change organization of student %s failed: %w", studentID, err)
+		}
+		updated += res.ModifiedCount
+	}
+	return updated, nil
+}
+
+func (r *reportStatusChangeRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "term_id", Value: 1}, {Key: "changed_at", Value: 1}}},
+		{Keys: bson.D{{Key: "organization_id", Value: 1}, {Key: "term_id", Value: 1}, {Key: "changed_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "report_id", Value: 1}, {Key: "section", Value: 1}, {Key: "changed_at", Value: 1}}},
+	})
+	return err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReportStatusModelRepository interface {
+	GetByOrganization(ctx context.Context, organizationID string) (*model.ReportStatusModel, error)
+	Upsert(ctx context.Context, statusModel *model.ReportStatusModel) error
+}
+
+type reportStatusModelRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReportStatusModelRepository(collection *mongo.Collection) ReportStatusModelRepository {
+	return &reportStatusModelRepository{collection}
+}
+
+// GetByOrganization trả về bộ status của organization, nil nếu chưa cấu hình
+func (r *reportStatusModelRepository) GetByOrganization(ctx context.Context, organizationID string) (*model.ReportStatusModel, error) {
+	var statusModel model.ReportStatusModel
+	err := r.collection.FindOne(ctx, bson.M{"organization_id": organizationID}).Decode(&statusModel)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &statusModel, nil
+}
+
+func (r *reportStatusModelRepository) Upsert(ctx context.Context, statusModel *model.ReportStatusModel) error {
+	statusModel.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"statuses":   statusModel.Statuses,
+			"updated_by": statusModel.UpdatedBy,
+			"updated_at": statusModel.UpdatedAt,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(ctx, bson.M{"organization_id": statusModel.OrganizationID}, update, opts)
+	return err
+}
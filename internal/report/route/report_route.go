@@ -4,33 +4,83 @@ import (
 	"report-service/internal/gateway"
 	"report-service/internal/middleware"
 	"report-service/internal/report/handler"
+	"report-service/internal/report/repository"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterReportRoutes(r *gin.Engine, h *handler.ReportHandler, rh *handler.ReportHistoryHandler, rph *handler.ReportPlanTemplateHandler, rth *handler.ReportTranslateHandler, userGw gateway.UserGateway) {
+func RegisterReportRoutes(r *gin.Engine, h *handler.ReportHandler, rh *handler.ReportHistoryHandler, rph *handler.ReportPlanTemplateHandler, rth *handler.ReportTranslateHandler, rsh *handler.ReportSchemaHandler, rsc *handler.ReportSectionHandler, rsm *handler.ReportStatusModelHandler, rdh *handler.ReportDeadlineHandler, reh *handler.ReportExportHandler, userGw gateway.UserGateway, idempotencyRepo repository.IdempotencyRepository) {
+	// Idempotency-Key cho các API ghi report (app retry khi mạng chập chờn)
+	idempotent := middleware.Idempotency(idempotencyRepo)
+
 	// Admin routes
 	adminGroup := r.Group("/api/v1/admin")
 	adminGroup.Use(middleware.Secured(userGw))
 	{
 		reportsAdmin := adminGroup.Group("/reports")
 		{
-			reportsAdmin.POST("", h.UploadReport4Web)
+			reportsAdmin.POST("", idempotent, h.UploadReport4Web)
 			reportsAdmin.POST("/get-report", h.GetReport4Web)
 			reportsAdmin.GET("/overview", h.GetReportOverViewAllClassroom4Web)
+			reportsAdmin.GET("/overview/export", h.ExportReportOverViewAllClassroom4Web)
+			reportsAdmin.GET("/review-queue", h.GetReviewQueue4Web)
+			reportsAdmin.GET("/analytics/review-sla", h.GetReviewSLA4Web)
+			reportsAdmin.POST("/send-back", idempotent, h.SendBackReport4Web)
+
+			// editing lock
+			reportsAdmin.POST("/lock", h.AcquireEditingLock4Web)
+			reportsAdmin.POST("/lock/renew", h.RenewEditingLock4Web)
+			reportsAdmin.POST("/lock/release", h.ReleaseEditingLock4Web)
+
+			// report_data schema
+			reportsAdmin.GET("/schema", rsh.GetReportSchema4Web)
+			reportsAdmin.PUT("/schema", rsh.UpdateReportSchema4Web)
+
+			// section tuỳ biến của organization
+			reportsAdmin.GET("/sections", rsc.GetSections4Web)
+			reportsAdmin.POST("/sections", rsc.CreateSection4Web)
+			reportsAdmin.PUT("/sections/:id", rsc.UpdateSection4Web)
+			reportsAdmin.DELETE("/sections/:id", rsc.DeleteSection4Web)
+
+			// bộ status + weight tính progress của organization
+			reportsAdmin.GET("/statuses", rsm.GetStatusModel4Web)
+			reportsAdmin.PUT("/statuses", rsm.UpdateStatusModel4Web)
+
+			// deadline theo term/topic/section
+			reportsAdmin.GET("/deadlines", rdh.GetDeadlines4Web)
+			reportsAdmin.POST("/deadlines", rdh.CreateDeadline4Web)
+			reportsAdmin.PUT("/deadlines/:id", rdh.UpdateDeadline4Web)
+			reportsAdmin.DELETE("/deadlines/:id", rdh.DeleteDeadline4Web)
+
+			// xuất file
+			reportsAdmin.GET("/export/pdf", reh.ExportReportPDF4Web)
+			reportsAdmin.GET("/report-card", reh.GetReportCard4Web)
+			reportsAdmin.GET("/export/report-card", reh.ExportReportCardHTML4Web)
+			reportsAdmin.GET("/export/docx", reh.ExportReportDOCX4Web)
+			reportsAdmin.GET("/export/report-card/docx", reh.ExportReportCardDOCX4Web)
+
+			// job xuất ZIP theo lớp, chỉ đọc được job của organization hiện tại
+			reportsAdmin.POST("/export/jobs", reh.CreateExportJob4Web)
+			reportsAdmin.GET("/export/jobs/:id", reh.GetExportJob4Web)
+			reportsAdmin.GET("/export/jobs/:id/download", reh.DownloadExportJob4Web)
 
 			// report history
-			reportsAdmin.GET("/histories", rh.GetByEditor4App)
+			reportsAdmin.GET("/histories", rh.Search4Web)
+			reportsAdmin.GET("/histories/:report_id/diffs", rh.GetDiffsByReport4Web)
+			reportsAdmin.POST("/histories/:history_id/restore", idempotent, h.RestoreReportFromHistory4Web)
 
 			// plan template
 			reportsClassroomAdmin := reportsAdmin.Group("/classrooms")
 			{
 				reportsClassroomAdmin.POST("/plan-templates", rph.UploadReportPlanTemplate)
-				reportsClassroomAdmin.POST("", h.UploadClassroomReport4Web)
+				reportsClassroomAdmin.POST("", idempotent, h.UploadClassroomReport4Web)
 				reportsClassroomAdmin.POST("/get-report", h.GetClassroomReports4Web)
-				reportsClassroomAdmin.POST("/templates/school/apply", h.ApplyTopicPlanTemplateIsSchool2Report)
-				reportsClassroomAdmin.POST("/templates/classroom/apply", h.ApplyTopicPlanTemplateIsClassroom2Report)
+				reportsClassroomAdmin.POST("/accept", idempotent, h.AcceptClassroomReports4Web)
+				reportsClassroomAdmin.GET("/events", h.StreamClassroomEvents4Web)
+				reportsClassroomAdmin.POST("/templates/school/apply", idempotent, h.ApplyTopicPlanTemplateIsSchool2Report)
+				reportsClassroomAdmin.POST("/templates/classroom/apply", idempotent, h.ApplyTopicPlanTemplateIsClassroom2Report)
 				reportsClassroomAdmin.GET("/overview", h.GetReportOverViewByClassroom4Web)
+				reportsClassroomAdmin.GET("/overview/export", h.ExportReportOverViewByClassroom4Web)
 			}
 
 			// report translate
@@ -49,10 +99,19 @@ func RegisterReportRoutes(r *gin.Engine, h *handler.ReportHandler, rh *handler.R
 	{
 		reportsUser := userGroup.Group("/reports")
 		{
-			reportsUser.POST("", h.UploadReport4App)
+			reportsUser.POST("", idempotent, h.UploadReport4App)
 			reportsUser.POST("/get-report", h.GetReport4App)
 			reportsUser.GET("/tasks", h.GetTeacherReportTasks4App)
 			reportsUser.GET("/histories", rh.GetByEditor4App)
+
+			// editing lock
+			reportsUser.POST("/lock", h.AcquireEditingLock4App)
+			reportsUser.POST("/lock/renew", h.RenewEditingLock4App)
+			reportsUser.POST("/lock/release", h.ReleaseEditingLock4App)
+
+			// offline sync
+			reportsUser.GET("/sync/changes", h.GetSyncChanges4App)
+			reportsUser.POST("/sync/push", idempotent, h.PushSync4App)
 		}
 	}
 }
@@ -0,0 +1,138 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"report-service/helper"
+	"report-service/internal/report/model"
+	"report-service/internal/report/workflow"
+	"report-service/pkg/constants"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ratingField: field lưu điểm của section kiểu rating
+const ratingField = "rating"
+
+var sectionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// progressSections: các section mặc định được tính vào progress
+var progressSections = []string{"before", "now", "conclusion"}
+
+// IsDefaultSection: section có sẵn trong schema mặc định, organization không được định nghĩa lại
+func IsDefaultSection(key string) bool {
+	_, ok := Default().Sections[key]
+	return ok
+}
+
+// SectionSchemaFor sinh schema cho section tuỳ biến: các field workflow như now/before
+// + field giá trị theo kiểu (rating → number, checklist → boolean cho từng mục)
+func SectionSchemaFor(def *model.ReportSectionDefinition) model.SectionSchema {
+	fields := sectionFields()
+
+	switch constants.ReportSectionType(def.Type) {
+	case constants.ReportSectionTypeRating:
+		fs := model.FieldSchema{Type: TypeNumber}
+		if def.Rating != nil {
+			min, max := float64(def.Rating.Min), float64(def.Rating.Max)
+			fs.Min, fs.Max = &min, &max
+		}
+		fields[ratingField] = fs
+	case constants.ReportSectionTypeChecklist:
+		for _, item := range def.Items {
+			fields[item.Key] = model.FieldSchema{Type: TypeBoolean}
+		}
+	}
+
+	return model.SectionSchema{Fields: fields}
+}
+
+// ProgressSections trả về các section tính vào progress: before/now/conclusion + section tuỳ biến có TrackProgress
+func ProgressSections(defs []*model.ReportSectionDefinition) []string {
+	sections := append([]string{}, progressSections...)
+	for _, def := range defs {
+		if def.TrackProgress {
+			sections = append(sections, def.Key)
+		}
+	}
+	return sections
+}
+
+// CheckSectionDefinition kiểm tra định nghĩa section tuỳ biến trước khi lưu
+func CheckSectionDefinition(def *model.ReportSectionDefinition) error {
+	var errs []FieldError
+
+	switch {
+	case !sectionKeyPattern.MatchString(def.Key):
+		errs = append(errs, FieldError{Path: "key", Message: "must match " + sectionKeyPattern.String()})
+	case IsDefaultSection(def.Key):
+		errs = append(errs, FieldError{Path: "key", Message: "is a built-in section"})
+	}
+
+	switch constants.ReportSectionType(def.Type) {
+	case constants.ReportSectionTypeText:
+		if def.Rating != nil || len(def.Items) > 0 {
+			errs = append(errs, FieldError{Path: "type", Message: "text section has no rating or items"})
+		}
+	case constants.ReportSectionTypeRating:
+		if def.Rating == nil || def.Rating.Min >= def.Rating.Max {
+			errs = append(errs, FieldError{Path: "rating", Message: "min must be less than max"})
+		}
+		if len(def.Items) > 0 {
+			errs = append(errs, FieldError{Path: "items", Message: "rating section has no items"})
+		}
+	case constants.ReportSectionTypeChecklist:
+		if len(def.Items) == 0 {
+			errs = append(errs, FieldError{Path: "items", Message: "checklist section needs at least one item"})
+		}
+		if def.Rating != nil {
+			errs = append(errs, FieldError{Path: "rating", Message: "checklist section has no rating"})
+		}
+		reserved := sectionFields()
+		seen := make(map[string]bool, len(def.Items))
+		for i, item := range def.Items {
+			path := fmt.Sprintf("items[%d].key", i)
+			_, isReserved := reserved[item.Key]
+			switch {
+			case !sectionKeyPattern.MatchString(item.Key):
+				errs = append(errs, FieldError{Path: path, Message: "must match " + sectionKeyPattern.String()})
+			case isReserved || item.Key == ratingField:
+				errs = append(errs, FieldError{Path: path, Message: "is a reserved field name"})
+			case seen[item.Key]:
+				errs = append(errs, FieldError{Path: path, Message: "duplicate item key"})
+			}
+			seen[item.Key] = true
+		}
+	default:
+		errs = append(errs, FieldError{Path: "type", Message: fmt.Sprintf("must be one of %s, %s, %s",
+			constants.ReportSectionTypeText, constants.ReportSectionTypeRating, constants.ReportSectionTypeChecklist)})
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// SectionProgress tính điểm từng section tính progress (before/now/conclusion + section tuỳ biến có TrackProgress)
+// theo bộ status của organization, kèm tổng đã quy về thang của 3 section mặc định
+func SectionProgress(reportData bson.M, defs []*model.ReportSectionDefinition, progress *workflow.Progress) (map[string]float32, float32) {
+	sections := ProgressSections(defs)
+	values := make(map[string]float32, len(sections))
+	var sum float32
+	for _, section := range sections {
+		status, _ := helper.ToBsonM(reportData[section])["status"].(string)
+		values[section] = progress.Value(status)
+		sum += values[section]
+	}
+	return values, ScaleProgress(sum, len(sections))
+}
+
+// ScaleProgress quy tổng status của sections về thang của 3 section mặc định,
+// để main_percentage không đổi ý nghĩa khi organization thêm section
+func ScaleProgress(sum float32, sections int) float32 {
+	if sections == 0 {
+		return 0
+	}
+	return sum * float32(len(progressSections)) / float32(sections)
+}
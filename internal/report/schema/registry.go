@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"context"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+)
+
+// Registry cung cấp schema hiệu lực (mặc định + section tuỳ biến + mở rộng) của từng organization
+type Registry interface {
+	Get(ctx context.Context, organizationID string) (model.ReportSchema, error)
+	Validate(ctx context.Context, organizationID string, data map[string]interface{}) error
+	CustomSections(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error)
+}
+
+type registry struct {
+	repo        repository.ReportSchemaRepository
+	sectionRepo repository.ReportSectionRepository
+}
+
+func NewRegistry(repo repository.ReportSchemaRepository, sectionRepo repository.ReportSectionRepository) Registry {
+	return &registry{repo: repo, sectionRepo: sectionRepo}
+}
+
+func (r *registry) Get(ctx context.Context, organizationID string) (model.ReportSchema, error) {
+	if organizationID == "" {
+		return Default(), nil
+	}
+
+	defs, err := r.sectionRepo.GetByOrganization(ctx, organizationID)
+	if err != nil {
+		return model.ReportSchema{}, err
+	}
+	base := Default()
+	for _, def := range defs {
+		base.Sections[def.Key] = SectionSchemaFor(def)
+	}
+
+	// section đã xoá vẫn nhận dữ liệu để app gửi lại report cũ không bị từ chối
+	retired, err := r.sectionRepo.GetRetiredByOrganization(ctx, organizationID)
+	if err != nil {
+		return model.ReportSchema{}, err
+	}
+	for _, def := range retired {
+		if _, ok := base.Sections[def.Key]; !ok {
+			base.Sections[def.Key] = SectionSchemaFor(def)
+		}
+	}
+
+	ext, err := r.repo.GetByOrganization(ctx, organizationID)
+	if err != nil {
+		return model.ReportSchema{}, err
+	}
+
+	merged := Merge(base, ext)
+	merged.OrganizationID = organizationID
+	return merged, nil
+}
+
+func (r *registry) Validate(ctx context.Context, organizationID string, data map[string]interface{}) error {
+	s, err := r.Get(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	return Validate(s, data)
+}
+
+func (r *registry) CustomSections(ctx context.Context, organizationID string) ([]*model.ReportSectionDefinition, error) {
+	if organizationID == "" {
+		return nil, nil
+	}
+	return r.sectionRepo.GetByOrganization(ctx, organizationID)
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"report-service/internal/report/model"
+)
+
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+)
+
+const (
+	maxTextLength = 10000
+	maxMetaLength = 64
+)
+
+// sectionFields: section teacher viết + manager review (now, before, ...)
+func sectionFields() map[string]model.FieldSchema {
+	return map[string]model.FieldSchema{
+		"color":              {Type: TypeString, MaxLength: 32},
+		"content":            {Type: TypeString, MaxLength: maxTextLength},
+		"status":             {Type: TypeString, MaxLength: 32},
+		"teacher_report":     {Type: TypeString, MaxLength: maxTextLength},
+		"manager_comment":    {Type: TypeString, MaxLength: maxTextLength},
+		"manager_note":       {Type: TypeString, MaxLength: maxTextLength},
+		"note_for_teacher":   {Type: TypeString, MaxLength: maxTextLength},
+		"updated_at":         {Type: TypeString, MaxLength: maxMetaLength},
+		"manager_updated_at": {Type: TypeString, MaxLength: maxMetaLength},
+		"latest_update_time": {Type: TypeString, MaxLength: maxMetaLength},
+	}
+}
+
+// contentFields: section chỉ có nội dung (title, goal, ...)
+func contentFields() map[string]model.FieldSchema {
+	return map[string]model.FieldSchema{
+		"content":    {Type: TypeString, MaxLength: maxTextLength},
+		"updated_at": {Type: TypeString, MaxLength: maxMetaLength},
+	}
+}
+
+// Default là schema chung cho mọi organization, theo cấu trúc model.ReportData
+func Default() model.ReportSchema {
+	return model.ReportSchema{
+		Sections: map[string]model.SectionSchema{
+			"before":          {Fields: sectionFields()},
+			"now":             {Fields: sectionFields()},
+			"conclusion":      {Fields: sectionFields()},
+			"introduction":    {Fields: sectionFields()},
+			"note":            {Fields: sectionFields()},
+			"title":           {Fields: contentFields()},
+			"sub_title":       {Fields: contentFields()},
+			"goal":            {Fields: contentFields()},
+			"curriculum_area": {Fields: contentFields()},
+			"previous_term":   {Fields: contentFields()},
+		},
+	}
+}
+
+// Merge gộp phần mở rộng của organization vào base: thêm section/field mới, field trùng tên thì ghi đè
+func Merge(base model.ReportSchema, ext *model.ReportSchema) model.ReportSchema {
+	merged := model.ReportSchema{Sections: make(map[string]model.SectionSchema, len(base.Sections))}
+	for name, section := range base.Sections {
+		fields := make(map[string]model.FieldSchema, len(section.Fields))
+		for field, fs := range section.Fields {
+			fields[field] = fs
+		}
+		merged.Sections[name] = model.SectionSchema{Fields: fields}
+	}
+
+	if ext == nil {
+		return merged
+	}
+
+	merged.OrganizationID = ext.OrganizationID
+	merged.UpdatedBy = ext.UpdatedBy
+	merged.UpdatedAt = ext.UpdatedAt
+	for name, section := range ext.Sections {
+		current, ok := merged.Sections[name]
+		if !ok {
+			current = model.SectionSchema{Fields: map[string]model.FieldSchema{}}
+		}
+		for field, fs := range section.Fields {
+			current.Fields[field] = fs
+		}
+		merged.Sections[name] = current
+	}
+
+	return merged
+}
@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"report-service/internal/report/model"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrInvalidReportData = errors.New("invalid report_data")
+
+// FieldError: lỗi tại một đường dẫn cụ thể, vd report_data.now.teacher_report
+type FieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ValidationError gom tất cả lỗi của payload để client sửa một lần
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Path+": "+fe.Message)
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidReportData, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidReportData
+}
+
+// Validate kiểm tra report_data theo schema, trả về *ValidationError nếu có lỗi
+func Validate(s model.ReportSchema, data map[string]interface{}) error {
+	var errs []FieldError
+
+	for _, section := range sortedKeys(data) {
+		path := "report_data." + section
+
+		sectionSchema, ok := s.Sections[section]
+		if !ok {
+			errs = append(errs, FieldError{Path: path, Message: "unknown section"})
+			continue
+		}
+
+		if data[section] == nil {
+			continue
+		}
+		fields, ok := data[section].(map[string]interface{})
+		if !ok {
+			errs = append(errs, FieldError{Path: path, Message: "must be an object"})
+			continue
+		}
+
+		for _, field := range sortedKeys(fields) {
+			fieldPath := path + "." + field
+
+			fieldSchema, ok := sectionSchema.Fields[field]
+			if !ok {
+				errs = append(errs, FieldError{Path: fieldPath, Message: "unknown field"})
+				continue
+			}
+			if msg := checkValue(fieldSchema, fields[field]); msg != "" {
+				errs = append(errs, FieldError{Path: fieldPath, Message: msg})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// CheckSchema kiểm tra định nghĩa schema (phần mở rộng của organization) có hợp lệ không
+func CheckSchema(s model.ReportSchema) error {
+	var errs []FieldError
+
+	for _, section := range sortedKeys(s.Sections) {
+		path := "sections." + section
+		if strings.TrimSpace(section) == "" || strings.ContainsAny(section, ".$") {
+			errs = append(errs, FieldError{Path: path, Message: "invalid section name"})
+			continue
+		}
+		for _, field := range sortedKeys(s.Sections[section].Fields) {
+			fieldPath := path + "." + field
+			fs := s.Sections[section].Fields[field]
+			if strings.TrimSpace(field) == "" || strings.ContainsAny(field, ".$") {
+				errs = append(errs, FieldError{Path: fieldPath, Message: "invalid field name"})
+				continue
+			}
+			switch fs.Type {
+			case TypeString, TypeNumber, TypeBoolean:
+			default:
+				errs = append(errs, FieldError{Path: fieldPath + ".type", Message: fmt.Sprintf("must be one of %s, %s, %s", TypeString, TypeNumber, TypeBoolean)})
+			}
+			if fs.MaxLength < 0 {
+				errs = append(errs, FieldError{Path: fieldPath + ".max_length", Message: "must not be negative"})
+			}
+			if fs.Min != nil && fs.Max != nil && *fs.Min > *fs.Max {
+				errs = append(errs, FieldError{Path: fieldPath + ".min", Message: "must not be greater than max"})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func checkValue(fs model.FieldSchema, value interface{}) string {
+	// null = xoá giá trị, luôn hợp lệ
+	if value == nil {
+		return ""
+	}
+
+	switch fs.Type {
+	case TypeString:
+		str, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if fs.MaxLength > 0 && utf8.RuneCountInString(str) > fs.MaxLength {
+			return fmt.Sprintf("must be at most %d characters", fs.MaxLength)
+		}
+	case TypeNumber:
+		var num float64
+		switch v := value.(type) {
+		case float64:
+			num = v
+		case float32:
+			num = float64(v)
+		case int:
+			num = float64(v)
+		case int32:
+			num = float64(v)
+		case int64:
+			num = float64(v)
+		default:
+			return "must be a number"
+		}
+		if fs.Min != nil && num < *fs.Min {
+			return fmt.Sprintf("must be at least %v", *fs.Min)
+		}
+		if fs.Max != nil && num > *fs.Max {
+			return fmt.Sprintf("must be at most %v", *fs.Max)
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
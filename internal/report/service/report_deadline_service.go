@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportDeadlineService interface {
+	GetDeadlines4Web(ctx context.Context, req request.GetReportDeadlinesRequest4Web) ([]*model.ReportDeadline, error)
+	CreateDeadline4Web(ctx context.Context, req request.ReportDeadlineRequest4Web) (*model.ReportDeadline, error)
+	UpdateDeadline4Web(ctx context.Context, id string, req request.ReportDeadlineRequest4Web) (*model.ReportDeadline, error)
+	DeleteDeadline4Web(ctx context.Context, id string) error
+}
+
+type reportDeadlineService struct {
+	repo repository.ReportDeadlineRepository
+}
+
+func NewReportDeadlineService(repo repository.ReportDeadlineRepository) ReportDeadlineService {
+	return &reportDeadlineService{repo: repo}
+}
+
+func (s *reportDeadlineService) GetDeadlines4Web(ctx context.Context, req request.GetReportDeadlinesRequest4Web) ([]*model.ReportDeadline, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadlines, err := s.repo.GetByOrganization(ctx, organizationID, req.TermID)
+	if err != nil {
+		return nil, err
+	}
+	if deadlines == nil {
+		deadlines = []*model.ReportDeadline{}
+	}
+	return deadlines, nil
+}
+
+func (s *reportDeadlineService) CreateDeadline4Web(ctx context.Context, req request.ReportDeadlineRequest4Web) (*model.ReportDeadline, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := &model.ReportDeadline{
+		OrganizationID: organizationID,
+		TermID:         req.TermID,
+		TopicID:        req.TopicID,
+		Section:        req.Section,
+		DueAt:          req.DueAt,
+		CreatedBy:      helper.GetUserID(ctx),
+		UpdatedBy:      helper.GetUserID(ctx),
+	}
+
+	if err := s.repo.Create(ctx, deadline); err != nil {
+		return nil, err
+	}
+	return deadline, nil
+}
+
+func (s *reportDeadlineService) UpdateDeadline4Web(ctx context.Context, id string, req request.ReportDeadlineRequest4Web) (*model.ReportDeadline, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid deadline id")
+	}
+
+	deadline, err := s.repo.GetByID(ctx, organizationID, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline.TermID = req.TermID
+	deadline.TopicID = req.TopicID
+	deadline.Section = req.Section
+	deadline.DueAt = req.DueAt
+	deadline.UpdatedBy = helper.GetUserID(ctx)
+
+	if err := s.repo.Update(ctx, deadline); err != nil {
+		return nil, err
+	}
+	return deadline, nil
+}
+
+func (s *reportDeadlineService) DeleteDeadline4Web(ctx context.Context, id string) error {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid deadline id")
+	}
+
+	return s.repo.Delete(ctx, organizationID, objID)
+}
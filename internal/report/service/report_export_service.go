@@ -0,0 +1,580 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"path"
+	"report-service/helper"
+	"report-service/internal/gateway"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/dto/response"
+	"report-service/internal/report/export"
+	"report-service/internal/report/exportjob"
+	"report-service/internal/report/mapper"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/logger"
+	"report-service/pkg/blobstore"
+	"report-service/pkg/constants"
+	"report-service/pkg/pdf"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const docxContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+var (
+	ErrExportReportNotFound = errors.New("report not found")
+	ErrExportJobNotReady    = errors.New("report export job is not completed")
+)
+
+type ReportExportService interface {
+	ExportReportPDF4Web(ctx context.Context, req request.ExportReportRequest4Web) (*response.ExportFile, error)
+	GetReportCard4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ReportCard4Web, error)
+	ExportReportCardHTML4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ExportFile, error)
+	ExportReportDOCX4Web(ctx context.Context, req request.ExportReportRequest4Web) (*response.ExportFile, error)
+	ExportReportCardDOCX4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ExportFile, error)
+	CreateExportJob4Web(ctx context.Context, req request.CreateReportExportJobRequest4Web) (*response.ReportExportJob4Web, error)
+	GetExportJob4Web(ctx context.Context, id string) (*response.ReportExportJob4Web, error)
+	OpenExportJobArtifact4Web(ctx context.Context, id string) (*model.ReportExportJob, io.ReadCloser, error)
+}
+
+type reportExportService struct {
+	reportRepo    repository.ReportRepository
+	translateRepo repository.ReportTranslateRepo
+	jobRepo       repository.ReportExportJobRepository
+	userGw        gateway.UserGateway
+	classroomGw   gateway.ClassroomGateway
+	termGw        gateway.TermGateway
+	mediaGw       gateway.MediaGateway
+	queue         *exportjob.Queue
+	store         blobstore.Store
+	fonts         *pdf.Fonts
+}
+
+func NewReportExportService(
+	reportRepo repository.ReportRepository,
+	translateRepo repository.ReportTranslateRepo,
+	jobRepo repository.ReportExportJobRepository,
+	userGw gateway.UserGateway,
+	classroomGw gateway.ClassroomGateway,
+	termGw gateway.TermGateway,
+	mediaGw gateway.MediaGateway,
+	queue *exportjob.Queue,
+	store blobstore.Store,
+	fonts *pdf.Fonts,
+) ReportExportService {
+	return &reportExportService{
+		reportRepo:    reportRepo,
+		translateRepo: translateRepo,
+		jobRepo:       jobRepo,
+		userGw:        userGw,
+		classroomGw:   classroomGw,
+		termGw:        termGw,
+		mediaGw:       mediaGw,
+		queue:         queue,
+		store:         store,
+		fonts:         fonts,
+	}
+}
+
+func (s *reportExportService) ExportReportPDF4Web(ctx context.Context, req request.ExportReportRequest4Web) (*response.ExportFile, error) {
+	data, images, err := s.singleReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := export.RenderReportPDF(data, images, s.fonts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ExportFile{
+		FileName:    fmt.Sprintf("report_%s_%s_%s.pdf", req.StudentID, req.TopicID, req.UniqueLangKey),
+		ContentType: "application/pdf",
+		Data:        content,
+	}, nil
+}
+
+// ExportReportDOCX4Web xuất report ra file Word để văn phòng chỉnh sửa trước khi in
+func (s *reportExportService) ExportReportDOCX4Web(ctx context.Context, req request.ExportReportRequest4Web) (*response.ExportFile, error) {
+	data, images, err := s.singleReport(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := export.RenderReportDOCX(data, images)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ExportFile{
+		FileName:    fmt.Sprintf("report_%s_%s_%s.docx", req.StudentID, req.TopicID, req.UniqueLangKey),
+		ContentType: docxContentType,
+		Data:        content,
+	}, nil
+}
+
+// singleReport: dữ liệu và ảnh để xuất một report
+func (s *reportExportService) singleReport(ctx context.Context, req request.ExportReportRequest4Web) (*response.GetReport2Print, export.Images, error) {
+	org, student, err := s.exportScope(ctx, req.StudentID)
+	if err != nil {
+		return nil, export.Images{}, err
+	}
+
+	report, err := s.reportRepo.GetByStudentTopicTermAndLanguage(ctx, req.StudentID, req.TopicID, req.TermID, req.UniqueLangKey)
+	if err != nil || report == nil {
+		return nil, export.Images{}, ErrExportReportNotFound
+	}
+
+	data := s.newLookup(org).printData(ctx, report, student.Name)
+	if req.IncludeComments {
+		data.Comments = mapper.MapReportComments(report)
+	}
+	images := export.Images{
+		OrganizationAvatar: fetchExportImage(ctx, data.OrganizationAvatar),
+		TopicImage:         fetchExportImage(ctx, data.TopicImage),
+	}
+	return data, images, nil
+}
+
+// GetReportCard4Web gom report của mọi topic của học sinh trong term,
+// kèm bản dịch sang target_language nếu có yêu cầu
+func (s *reportExportService) GetReportCard4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ReportCard4Web, error) {
+	org, student, err := s.exportScope(ctx, req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	topics, err := s.studentTopics(ctx, req.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := s.newLookup(org)
+	card := &response.ReportCard4Web{
+		OrganizationName:   org.OrganizationName,
+		OrganizationAvatar: org.AvatarURL,
+		StudentID:          student.ID,
+		StudentName:        student.Name,
+		TermID:             req.TermID,
+		TermTitle:          lookup.termTitle(ctx, req.TermID),
+		Language:           req.UniqueLangKey,
+		TargetLanguage:     req.TargetLanguage,
+		Topics:             make([]response.ReportCardTopic4Web, 0, len(topics)),
+		GeneratedAt:        time.Now(),
+	}
+
+	for _, topic := range topics {
+		// topic chưa có report trong term thì không đưa vào phiếu
+		report, err := s.reportRepo.GetByStudentTopicTermAndLanguage(ctx, req.StudentID, topic.ID, req.TermID, req.UniqueLangKey)
+		if err != nil || report == nil {
+			continue
+		}
+
+		content := mapper.MapReport2Print(report)
+		item := response.ReportCardTopic4Web{
+			Topic: response.Topic{
+				ID:           topic.ID,
+				Title:        topic.Title,
+				MainImageUrl: topic.MainImageUrl,
+			},
+			ReportID:    report.ID.Hex(),
+			TeacherName: lookup.teacherName(ctx, report.EditorID),
+			Status:      report.Status,
+			Before:      content.Before,
+			Now:         content.Now,
+			Conclusion:  content.Conclusion,
+		}
+		if req.IncludeComments {
+			item.Comments = mapper.MapReportComments(report)
+		}
+
+		if req.TargetLanguage != "" {
+			translation, err := s.translateRepo.FindByStudentTopicTerm(ctx, req.StudentID, topic.ID, req.TermID)
+			if err != nil {
+				return nil, err
+			}
+			if translation != nil {
+				if data, ok := translation.Translations[req.TargetLanguage]; ok {
+					item.Translation = &data
+				}
+			}
+		}
+
+		card.Topics = append(card.Topics, item)
+	}
+
+	return card, nil
+}
+
+func (s *reportExportService) ExportReportCardHTML4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ExportFile, error) {
+	card, err := s.GetReportCard4Web(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := export.RenderReportCardHTML(card)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ExportFile{
+		FileName:    fmt.Sprintf("report_card_%s_%s.html", card.StudentID, card.TermID),
+		ContentType: "text/html; charset=utf-8",
+		Data:        content,
+		Inline:      true,
+	}, nil
+}
+
+func (s *reportExportService) ExportReportCardDOCX4Web(ctx context.Context, req request.GetReportCardRequest4Web) (*response.ExportFile, error) {
+	card, err := s.GetReportCard4Web(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	images := export.CardImages{
+		OrganizationAvatar: fetchExportImage(ctx, card.OrganizationAvatar),
+		Topics:             make(map[string]image.Image, len(card.Topics)),
+	}
+	for _, t := range card.Topics {
+		images.Topics[t.Topic.ID] = fetchExportImage(ctx, t.Topic.MainImageUrl)
+	}
+
+	content, err := export.RenderReportCardDOCX(card, images)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.ExportFile{
+		FileName:    fmt.Sprintf("report_card_%s_%s.docx", card.StudentID, card.TermID),
+		ContentType: docxContentType,
+		Data:        content,
+	}, nil
+}
+
+// CreateExportJob4Web tạo job xuất report của cả lớp, worker chạy nền và đóng gói thành ZIP
+func (s *reportExportService) CreateExportJob4Web(ctx context.Context, req request.CreateReportExportJobRequest4Web) (*response.ReportExportJob4Web, error) {
+	org, err := exportOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.ReportExportJob{
+		OrganizationID: org.ID,
+		ClassroomID:    req.ClassroomID,
+		TermID:         req.TermID,
+		TopicID:        req.TopicID,
+		Language:       req.UniqueLangKey,
+		Format:         req.Format,
+		Status:         string(constants.ReportExportJobQueued),
+		CreatedBy:      helper.GetUserID(ctx),
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// map trước khi submit vì worker sẽ cập nhật job
+	res := mapper.MapReportExportJob(job)
+	if err := s.queue.Submit(ctx, job, s); err != nil {
+		now := time.Now()
+		job.Status = string(constants.ReportExportJobFailed)
+		job.Error = err.Error()
+		job.FinishedAt = &now
+		if updateErr := s.jobRepo.Update(ctx, job); updateErr != nil {
+			return nil, updateErr
+		}
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (s *reportExportService) GetExportJob4Web(ctx context.Context, id string) (*response.ReportExportJob4Web, error) {
+	job, err := s.getExportJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := mapper.MapReportExportJob(job)
+	return &res, nil
+}
+
+// OpenExportJobArtifact4Web mở file ZIP của job đã xong, người gọi phải Close reader
+func (s *reportExportService) OpenExportJobArtifact4Web(ctx context.Context, id string) (*model.ReportExportJob, io.ReadCloser, error) {
+	job, err := s.getExportJob(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != string(constants.ReportExportJobCompleted) || job.ArtifactKey == "" {
+		return nil, nil, ErrExportJobNotReady
+	}
+
+	rc, err := s.store.Open(ctx, job.ArtifactKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, rc, nil
+}
+
+// Entries liệt kê report cần xuất của job: mỗi học sinh trong lớp x topic (một topic hoặc mọi topic)
+func (s *reportExportService) Entries(ctx context.Context, job *model.ReportExportJob) ([]exportjob.Entry, error) {
+	org, err := exportOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	students, err := s.classroomGw.GetStudentsByClassroomID(ctx, job.ClassroomID, job.TermID)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := s.newLookup(org)
+	var fixedTopics []gw_response.TopicResponse
+	if job.TopicID != "" {
+		topic := lookup.topic(ctx, job.TopicID)
+		if topic == nil {
+			return nil, fmt.Errorf("topic %s not found", job.TopicID)
+		}
+		fixedTopics = []gw_response.TopicResponse{*topic}
+	}
+
+	render := s.renderer(lookup, job.Format)
+	ext := string(constants.ReportExportFormatPDF)
+	switch job.Format {
+	case string(constants.ReportExportFormatHTML), string(constants.ReportExportFormatDOCX):
+		ext = job.Format
+	}
+
+	var entries []exportjob.Entry
+	for _, student := range students {
+		if student == nil || (student.OrganizationID != "" && student.OrganizationID != org.ID) {
+			continue
+		}
+
+		topics := fixedTopics
+		if topics == nil {
+			if topics, err = s.studentTopics(ctx, student.StudentID); err != nil {
+				return nil, err
+			}
+		}
+
+		folder := exportFileName(student.StudentName, student.StudentID)
+		for _, topic := range topics {
+			entry := exportjob.Entry{
+				StudentID:   student.StudentID,
+				StudentName: student.StudentName,
+				TopicID:     topic.ID,
+				TopicTitle:  topic.Title,
+				FileName:    path.Join(folder, exportFileName(topic.Title, topic.ID)+"."+ext),
+			}
+
+			report, err := s.reportRepo.GetByStudentTopicTermAndLanguage(ctx, student.StudentID, topic.ID, job.TermID, job.Language)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, err
+			}
+			if report != nil {
+				studentName := student.StudentName
+				entry.Render = func(ctx context.Context) ([]byte, error) {
+					return render(ctx, report, studentName)
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// renderer trả về hàm render một report theo định dạng của job
+func (s *reportExportService) renderer(lookup *exportLookup, format string) func(ctx context.Context, report *model.Report, studentName string) ([]byte, error) {
+	switch format {
+	case string(constants.ReportExportFormatHTML):
+		return func(ctx context.Context, report *model.Report, studentName string) ([]byte, error) {
+			return export.RenderReportHTML(lookup.printData(ctx, report, studentName))
+		}
+	case string(constants.ReportExportFormatDOCX):
+		return func(ctx context.Context, report *model.Report, studentName string) ([]byte, error) {
+			data := lookup.printData(ctx, report, studentName)
+			return export.RenderReportDOCX(data, export.Images{
+				OrganizationAvatar: lookup.image(ctx, data.OrganizationAvatar),
+				TopicImage:         lookup.image(ctx, data.TopicImage),
+			})
+		}
+	}
+
+	return func(ctx context.Context, report *model.Report, studentName string) ([]byte, error) {
+		data := lookup.printData(ctx, report, studentName)
+		return export.RenderReportPDF(data, export.Images{
+			OrganizationAvatar: lookup.image(ctx, data.OrganizationAvatar),
+			TopicImage:         lookup.image(ctx, data.TopicImage),
+		}, s.fonts)
+	}
+}
+
+func (s *reportExportService) getExportJob(ctx context.Context, id string) (*model.ReportExportJob, error) {
+	org, err := exportOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, repository.ErrExportJobNotFound
+	}
+	return s.jobRepo.GetByID(ctx, org.ID, objectID)
+}
+
+// studentTopics: topic của học sinh theo thứ tự tên
+func (s *reportExportService) studentTopics(ctx context.Context, studentID string) ([]gw_response.TopicResponse, error) {
+	topics, err := s.mediaGw.GetTopicByStudentID(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(topics, func(i, j int) bool {
+		a, b := strings.ToLower(topics[i].Title), strings.ToLower(topics[j].Title)
+		if a != b {
+			return a < b
+		}
+		return topics[i].ID < topics[j].ID
+	})
+	return topics, nil
+}
+
+// exportScope: chỉ xuất report của học sinh thuộc organization đang quản lý
+func (s *reportExportService) exportScope(ctx context.Context, studentID string) (*gw_response.OrganizationAdmin, *gw_response.StudentResponse, error) {
+	org, err := exportOrganization(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	student, err := s.userGw.GetStudentInfo(ctx, studentID)
+	if err != nil || student == nil || student.OrganizationID != org.ID {
+		return nil, nil, ErrExportReportNotFound
+	}
+	return org, student, nil
+}
+
+func exportOrganization(ctx context.Context) (*gw_response.OrganizationAdmin, error) {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil {
+		return nil, errors.New("current user not found")
+	}
+	if currentUser.IsSuperAdmin || currentUser.OrganizationAdmin == nil {
+		return nil, errors.New("only organization admins can export reports")
+	}
+	return currentUser.OrganizationAdmin, nil
+}
+
+// exportFileName: tên file/thư mục trong ZIP, luôn kèm id để không trùng
+func exportFileName(name, id string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 32 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return id
+	}
+	return name + "_" + id
+}
+
+// exportLookup cache tên teacher, topic, term và ảnh trong một lần xuất
+type exportLookup struct {
+	s        *reportExportService
+	org      *gw_response.OrganizationAdmin
+	teachers map[string]string
+	topics   map[string]*gw_response.TopicResponse
+	terms    map[string]string
+	images   map[string]image.Image
+}
+
+func (s *reportExportService) newLookup(org *gw_response.OrganizationAdmin) *exportLookup {
+	return &exportLookup{
+		s:        s,
+		org:      org,
+		teachers: make(map[string]string),
+		topics:   make(map[string]*gw_response.TopicResponse),
+		terms:    make(map[string]string),
+		images:   make(map[string]image.Image),
+	}
+}
+
+// printData bổ sung tên organization, học sinh, teacher, topic, term cho report cần in
+func (l *exportLookup) printData(ctx context.Context, report *model.Report, studentName string) *response.GetReport2Print {
+	data := mapper.MapReport2Print(report)
+	data.OrganizationName = l.org.OrganizationName
+	data.OrganizationAvatar = l.org.AvatarURL
+	data.StudentName = studentName
+	data.TeacherName = l.teacherName(ctx, report.EditorID)
+	data.TermTitle = l.termTitle(ctx, report.TermID)
+	if topic := l.topic(ctx, report.TopicID); topic != nil {
+		data.TopicTitle = topic.Title
+		data.TopicImage = topic.MainImageUrl
+	}
+	return data
+}
+
+func (l *exportLookup) teacherName(ctx context.Context, editorID string) string {
+	if editorID == "" {
+		return ""
+	}
+	name, ok := l.teachers[editorID]
+	if !ok {
+		if teacher, _ := l.s.userGw.GetTeacherInfo(ctx, editorID, l.org.ID); teacher != nil {
+			name = teacher.Name
+		}
+		l.teachers[editorID] = name
+	}
+	return name
+}
+
+func (l *exportLookup) topic(ctx context.Context, topicID string) *gw_response.TopicResponse {
+	topic, ok := l.topics[topicID]
+	if !ok {
+		topic, _ = l.s.mediaGw.GetTopicByID(ctx, topicID)
+		l.topics[topicID] = topic
+	}
+	return topic
+}
+
+func (l *exportLookup) termTitle(ctx context.Context, termID string) string {
+	title, ok := l.terms[termID]
+	if !ok {
+		if term, _ := l.s.termGw.GetTermByID(ctx, termID); term != nil {
+			title = term.Title
+		}
+		l.terms[termID] = title
+	}
+	return title
+}
+
+func (l *exportLookup) image(ctx context.Context, url string) image.Image {
+	img, ok := l.images[url]
+	if !ok {
+		img = fetchExportImage(ctx, url)
+		l.images[url] = img
+	}
+	return img
+}
+
+// fetchExportImage: ảnh lỗi thì bỏ qua, file vẫn được xuất không có ảnh
+func fetchExportImage(ctx context.Context, url string) image.Image {
+	if url == "" {
+		return nil
+	}
+	img, err := export.FetchImage(ctx, url)
+	if err != nil {
+		logger.WriteLogEx("warn", "fetch export image failed: "+err.Error(), map[string]interface{}{
+			"url": url,
+		})
+		return nil
+	}
+	return img
+}
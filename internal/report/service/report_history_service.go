@@ -2,23 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"report-service/helper"
+	"report-service/internal/report/dto/request"
 	"report-service/internal/report/dto/response"
 	"report-service/internal/report/mapper"
+	"report-service/internal/report/model"
 	"report-service/internal/report/repository"
 	"report-service/pkg/constants"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ReportHistoryService interface {
 	GetByEditor4App(ctx context.Context) ([]response.ReportHistoryResponse, error)
+	GetDiffsByReport4Web(ctx context.Context, reportID string) ([]response.ReportHistoryDiffResponse, error)
+	Search4Web(ctx context.Context, req request.SearchReportHistoryRequest4Web) (*response.SearchReportHistoryResponse4Web, error)
 }
 
 type reportHistoryService struct {
-	repo repository.ReportHistoryRepository
+	repo       repository.ReportHistoryRepository
+	reportRepo repository.ReportRepository
 }
 
-func NewReportHistoryService(repo repository.ReportHistoryRepository) ReportHistoryService {
-	return &reportHistoryService{repo: repo}
+func NewReportHistoryService(repo repository.ReportHistoryRepository, reportRepo repository.ReportRepository) ReportHistoryService {
+	return &reportHistoryService{repo: repo, reportRepo: reportRepo}
 }
 
 func (s reportHistoryService) GetByEditor4App(ctx context.Context) ([]response.ReportHistoryResponse, error) {
@@ -30,3 +39,82 @@ func (s reportHistoryService) GetByEditor4App(ctx context.Context) ([]response.R
 	}
 	return mapper.MapReportHistoryListToRes4App(histories), nil
 }
+
+func (s reportHistoryService) GetDiffsByReport4Web(ctx context.Context, reportID string) ([]response.ReportHistoryDiffResponse, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, errors.New("invalid report id")
+	}
+
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	histories, err := s.repo.GetByReportID(ctx, objID)
+	if err != nil {
+		return nil, err
+	}
+	// report của organization khác trả về như không tồn tại
+	if reportOrganization(report, histories) != organizationID {
+		return nil, mongo.ErrNoDocuments
+	}
+	return mapper.MapReportHistoryListToDiffRes(histories), nil
+}
+
+// reportOrganization: organization của report, report cũ chưa backfill thì lấy theo history mới nhất có organization
+func reportOrganization(report *model.Report, histories []*model.ReportHistory) string {
+	if report.OrganizationID != "" {
+		return report.OrganizationID
+	}
+	for i := len(histories) - 1; i >= 0; i-- {
+		if histories[i].OrganizationID != "" {
+			return histories[i].OrganizationID
+		}
+	}
+	return ""
+}
+
+func (s reportHistoryService) Search4Web(ctx context.Context, req request.SearchReportHistoryRequest4Web) (*response.SearchReportHistoryResponse4Web, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repository.ReportHistoryFilter{
+		OrganizationID: organizationID,
+		StudentID:      req.StudentID,
+		ClassroomID:    req.ClassroomID,
+		EditorID:       req.EditorID,
+		EditorRole:     req.EditorRole,
+		Type:           req.Type,
+		From:           req.From,
+		To:             req.To,
+	}
+
+	if req.ReportID != "" {
+		objID, err := primitive.ObjectIDFromHex(req.ReportID)
+		if err != nil {
+			return nil, errors.New("invalid report id")
+		}
+		filter.ReportID = &objID
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	histories, nextCursor, err := s.repo.Search(ctx, filter, req.Cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.SearchReportHistoryResponse4Web{
+		Items:      mapper.MapReportHistoryListToRes4App(histories),
+		NextCursor: nextCursor,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"report-service/helper"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/pkg/constants"
+)
+
+type ReportSchemaService interface {
+	GetReportSchema4Web(ctx context.Context) (model.ReportSchema, error)
+	UpdateReportSchema4Web(ctx context.Context, req request.UpdateReportSchemaRequest4Web) (model.ReportSchema, error)
+}
+
+type reportSchemaService struct {
+	repo     repository.ReportSchemaRepository
+	registry schema.Registry
+}
+
+func NewReportSchemaService(repo repository.ReportSchemaRepository, registry schema.Registry) ReportSchemaService {
+	return &reportSchemaService{
+		repo:     repo,
+		registry: registry,
+	}
+}
+
+func (s *reportSchemaService) GetReportSchema4Web(ctx context.Context) (model.ReportSchema, error) {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil {
+		return model.ReportSchema{}, errors.New("current user not found")
+	}
+	return s.registry.Get(ctx, currentUser.OrganizationAdmin.ID)
+}
+
+// UpdateReportSchema4Web ghi đè phần mở rộng schema của organization, trả về schema hiệu lực sau khi lưu
+func (s *reportSchemaService) UpdateReportSchema4Web(ctx context.Context, req request.UpdateReportSchemaRequest4Web) (model.ReportSchema, error) {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil {
+		return model.ReportSchema{}, errors.New("current user not found")
+	}
+	if currentUser.IsSuperAdmin {
+		return model.ReportSchema{}, errors.New("super admin can't update report schema")
+	}
+
+	ext := &model.ReportSchema{
+		OrganizationID: currentUser.OrganizationAdmin.ID,
+		Sections:       req.Sections,
+		UpdatedBy:      helper.GetUserID(ctx),
+	}
+	if err := schema.CheckSchema(*ext); err != nil {
+		return model.ReportSchema{}, err
+	}
+
+	if err := s.repo.Upsert(ctx, ext); err != nil {
+		return model.ReportSchema{}, err
+	}
+
+	return s.registry.Get(ctx, ext.OrganizationID)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"report-service/helper"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/pkg/constants"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReportSectionService interface {
+	GetSections4Web(ctx context.Context) ([]*model.ReportSectionDefinition, error)
+	CreateSection4Web(ctx context.Context, req request.CreateReportSectionRequest4Web) (*model.ReportSectionDefinition, error)
+	UpdateSection4Web(ctx context.Context, id string, req request.UpdateReportSectionRequest4Web) (*model.ReportSectionDefinition, error)
+	DeleteSection4Web(ctx context.Context, id string) error
+}
+
+type reportSectionService struct {
+	repo repository.ReportSectionRepository
+}
+
+func NewReportSectionService(repo repository.ReportSectionRepository) ReportSectionService {
+	return &reportSectionService{repo: repo}
+}
+
+func (s *reportSectionService) GetSections4Web(ctx context.Context) ([]*model.ReportSectionDefinition, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := s.repo.GetByOrganization(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	if sections == nil {
+		sections = []*model.ReportSectionDefinition{}
+	}
+	return sections, nil
+}
+
+func (s *reportSectionService) CreateSection4Web(ctx context.Context, req request.CreateReportSectionRequest4Web) (*model.ReportSectionDefinition, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	section := &model.ReportSectionDefinition{
+		OrganizationID: organizationID,
+		Key:            req.Key,
+		Title:          req.Title,
+		Type:           req.Type,
+		Rating:         req.Rating,
+		Items:          req.Items,
+		Order:          req.Order,
+		TrackProgress:  req.TrackProgress,
+		CreatedBy:      helper.GetUserID(ctx),
+		UpdatedBy:      helper.GetUserID(ctx),
+	}
+	if err := schema.CheckSectionDefinition(section); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, section); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+func (s *reportSectionService) UpdateSection4Web(ctx context.Context, id string, req request.UpdateReportSectionRequest4Web) (*model.ReportSectionDefinition, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, errors.New("invalid section id")
+	}
+
+	section, err := s.repo.GetByID(ctx, organizationID, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	section.Title = req.Title
+	section.Type = req.Type
+	section.Rating = req.Rating
+	section.Items = req.Items
+	section.Order = req.Order
+	section.TrackProgress = req.TrackProgress
+	section.UpdatedBy = helper.GetUserID(ctx)
+	if err := schema.CheckSectionDefinition(section); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, section); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+// DeleteSection4Web chỉ ẩn định nghĩa (retired), dữ liệu đã nhập trong report_data được giữ nguyên
+func (s *reportSectionService) DeleteSection4Web(ctx context.Context, id string) error {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return errors.New("invalid section id")
+	}
+
+	return s.repo.Delete(ctx, organizationID, objID)
+}
+
+// currentOrganization: organization manager đang quản lý
+func currentOrganization(ctx context.Context) (string, error) {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil {
+		return "", errors.New("current user not found")
+	}
+	if currentUser.IsSuperAdmin {
+		return "", errors.New("super admin can't manage report sections")
+	}
+	return currentUser.OrganizationAdmin.ID, nil
+}
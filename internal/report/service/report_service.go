@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"report-service/internal/report/dto/request"
 	"report-service/internal/report/dto/response"
+	"report-service/internal/report/event"
+	"report-service/internal/report/export"
+	"report-service/internal/report/model"
 	"report-service/internal/report/usecase"
+	"report-service/pkg/constants"
 )
 
 type ReportService interface {
-	UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) error
-	UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) error
+	UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) (*response.UploadReportResponse, error)
+	UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) (*response.UploadReportResponse, error)
 	GetReport4App(ctx context.Context, req *request.GetReportRequest4App) (response.ReportResponse, error)
 	GetReport4Web(ctx context.Context, req *request.GetReportRequest4Web) (response.ReportResponse, error)
 	GetTeacherReportTasks4App(ctx context.Context) ([]response.GetTeacherReportTasksResponse4App, error)
@@ -19,6 +24,18 @@ type ReportService interface {
 	ApplyTopicPlanTemplateIsClassroom2Report(ctx context.Context, req request.ApplyTemplateIsClassroomToReportRequest) error
 	GetReportOverViewAllClassroom4Web(ctx context.Context, req request.GetReportOverViewAllClassroomRequest) (*response.GetReportOverviewAllClassroomResponse4Web, error)
 	GetReportOverViewByClassroom4Web(ctx context.Context, req request.GetReportOverViewByClassroomRequest) (*response.GetReportOverviewByClassroomResponse4Web, error)
+	ExportReportOverViewAllClassroom4Web(ctx context.Context, req request.ExportReportOverViewAllClassroomRequest4Web) (*response.ExportFile, error)
+	ExportReportOverViewByClassroom4Web(ctx context.Context, req request.ExportReportOverViewByClassroomRequest4Web) (*response.ExportFile, error)
+	SendBackReport4Web(ctx context.Context, req request.SendBackReportRequest4Web) error
+	AcceptClassroomReports4Web(ctx context.Context, req request.AcceptClassroomReportRequest4Web) (*response.AcceptClassroomReportResponse4Web, error)
+	RestoreReportFromHistory4Web(ctx context.Context, historyID string, req request.RestoreReportRequest4Web) error
+	UpdateEditingLock4App(ctx context.Context, req request.EditingLockRequest4App, action constants.EditingLockAction) (*model.EditingLock, error)
+	UpdateEditingLock4Web(ctx context.Context, req request.EditingLockRequest4Web, action constants.EditingLockAction) (*model.EditingLock, error)
+	SubscribeClassroomEvents4Web(ctx context.Context, req request.ClassroomReportEventRequest4Web) (*event.Subscription, error)
+	GetReviewQueue4Web(ctx context.Context, req request.GetReviewQueueRequest4Web) (*response.ReviewQueueResponse4Web, error)
+	GetReviewSLA4Web(ctx context.Context, req request.GetReviewSLARequest4Web) (*response.ReviewSLAResponse4Web, error)
+	GetSyncChanges4App(ctx context.Context, req request.SyncChangesRequest4App) (*response.SyncChangesResponse4App, error)
+	PushSync4App(ctx context.Context, req request.SyncPushRequest4App) (*response.SyncPushResponse4App, error)
 }
 
 type reportService struct {
@@ -36,7 +53,7 @@ func NewReportService(
 	}
 }
 
-func (s *reportService) UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) error {
+func (s *reportService) UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) (*response.UploadReportResponse, error) {
 	return s.appUsecase.UploadReport4App(ctx, req)
 }
 
@@ -52,7 +69,7 @@ func (s *reportService) GetReport4Web(ctx context.Context, req *request.GetRepor
 	return s.webUsecase.GetReport4Web(ctx, req)
 }
 
-func (s *reportService) UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) error {
+func (s *reportService) UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) (*response.UploadReportResponse, error) {
 	return s.webUsecase.UploadReport4Web(ctx, req)
 }
 
@@ -76,6 +93,91 @@ func (s *reportService) GetReportOverViewByClassroom4Web(ctx context.Context, re
 	return s.webUsecase.GetReportOverViewByClassroom4Web(ctx, req)
 }
 
+// ExportReportOverViewAllClassroom4Web xuất ma trận lớp x topic ra CSV/XLSX
+func (s *reportService) ExportReportOverViewAllClassroom4Web(ctx context.Context, req request.ExportReportOverViewAllClassroomRequest4Web) (*response.ExportFile, error) {
+	res, err := s.webUsecase.GetReportOverViewAllClassroom4Web(ctx, request.GetReportOverViewAllClassroomRequest{TermID: req.TermID})
+	if err != nil {
+		return nil, err
+	}
+	return overviewFile(export.ClassroomOverviewTable(res), req.Format, fmt.Sprintf("overview_%s", req.TermID))
+}
+
+// ExportReportOverViewByClassroom4Web xuất ma trận học sinh x topic của một lớp ra CSV/XLSX
+func (s *reportService) ExportReportOverViewByClassroom4Web(ctx context.Context, req request.ExportReportOverViewByClassroomRequest4Web) (*response.ExportFile, error) {
+	res, err := s.webUsecase.GetReportOverViewByClassroom4Web(ctx, request.GetReportOverViewByClassroomRequest{
+		TermID:      req.TermID,
+		ClassroomID: req.ClassroomID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overviewFile(export.StudentOverviewTable(res), req.Format, fmt.Sprintf("overview_%s_%s", req.ClassroomID, req.TermID))
+}
+
+func overviewFile(table *export.OverviewTable, format, name string) (*response.ExportFile, error) {
+	if format == string(constants.ReportExportFormatXLSX) {
+		content, err := table.XLSX()
+		if err != nil {
+			return nil, err
+		}
+		return &response.ExportFile{
+			FileName:    name + ".xlsx",
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Data:        content,
+		}, nil
+	}
+
+	content, err := table.CSV()
+	if err != nil {
+		return nil, err
+	}
+	return &response.ExportFile{
+		FileName:    name + ".csv",
+		ContentType: "text/csv; charset=utf-8",
+		Data:        content,
+	}, nil
+}
+
 func (s *reportService) GetClassroomReports4Web(ctx context.Context, req request.GetClassroomReportRequest4Web) (*response.GetClassroomReportResponse4Web, error) {
 	return s.webUsecase.GetClassroomReports4Web(ctx, req)
 }
+
+func (s *reportService) SendBackReport4Web(ctx context.Context, req request.SendBackReportRequest4Web) error {
+	return s.webUsecase.SendBackReport4Web(ctx, req)
+}
+
+func (s *reportService) AcceptClassroomReports4Web(ctx context.Context, req request.AcceptClassroomReportRequest4Web) (*response.AcceptClassroomReportResponse4Web, error) {
+	return s.webUsecase.AcceptClassroomReports4Web(ctx, req)
+}
+
+func (s *reportService) RestoreReportFromHistory4Web(ctx context.Context, historyID string, req request.RestoreReportRequest4Web) error {
+	return s.webUsecase.RestoreReportFromHistory4Web(ctx, historyID, req)
+}
+
+func (s *reportService) UpdateEditingLock4App(ctx context.Context, req request.EditingLockRequest4App, action constants.EditingLockAction) (*model.EditingLock, error) {
+	return s.appUsecase.UpdateEditingLock4App(ctx, req, action)
+}
+
+func (s *reportService) UpdateEditingLock4Web(ctx context.Context, req request.EditingLockRequest4Web, action constants.EditingLockAction) (*model.EditingLock, error) {
+	return s.webUsecase.UpdateEditingLock4Web(ctx, req, action)
+}
+
+func (s *reportService) SubscribeClassroomEvents4Web(ctx context.Context, req request.ClassroomReportEventRequest4Web) (*event.Subscription, error) {
+	return s.webUsecase.SubscribeClassroomEvents4Web(ctx, req)
+}
+
+func (s *reportService) GetReviewQueue4Web(ctx context.Context, req request.GetReviewQueueRequest4Web) (*response.ReviewQueueResponse4Web, error) {
+	return s.webUsecase.GetReviewQueue4Web(ctx, req)
+}
+
+func (s *reportService) GetReviewSLA4Web(ctx context.Context, req request.GetReviewSLARequest4Web) (*response.ReviewSLAResponse4Web, error) {
+	return s.webUsecase.GetReviewSLA4Web(ctx, req)
+}
+
+func (s *reportService) GetSyncChanges4App(ctx context.Context, req request.SyncChangesRequest4App) (*response.SyncChangesResponse4App, error) {
+	return s.appUsecase.GetSyncChanges4App(ctx, req)
+}
+
+func (s *reportService) PushSync4App(ctx context.Context, req request.SyncPushRequest4App) (*response.SyncPushResponse4App, error) {
+	return s.appUsecase.PushSync4App(ctx, req)
+}
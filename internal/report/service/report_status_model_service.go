@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/internal/report/workflow"
+)
+
+type ReportStatusModelService interface {
+	GetStatusModel4Web(ctx context.Context) (model.ReportStatusModel, error)
+	UpdateStatusModel4Web(ctx context.Context, req request.UpdateReportStatusModelRequest4Web) (model.ReportStatusModel, error)
+}
+
+type reportStatusModelService struct {
+	repo repository.ReportStatusModelRepository
+}
+
+func NewReportStatusModelService(repo repository.ReportStatusModelRepository) ReportStatusModelService {
+	return &reportStatusModelService{repo: repo}
+}
+
+// GetStatusModel4Web trả về bộ status đang dùng, mặc định nếu organization chưa cấu hình
+func (s *reportStatusModelService) GetStatusModel4Web(ctx context.Context) (model.ReportStatusModel, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return model.ReportStatusModel{}, err
+	}
+
+	statusModel, err := s.repo.GetByOrganization(ctx, organizationID)
+	if err != nil {
+		return model.ReportStatusModel{}, err
+	}
+	if statusModel == nil {
+		res := workflow.DefaultStatusModel()
+		res.OrganizationID = organizationID
+		return res, nil
+	}
+
+	workflow.SortStatuses(statusModel)
+	return *statusModel, nil
+}
+
+func (s *reportStatusModelService) UpdateStatusModel4Web(ctx context.Context, req request.UpdateReportStatusModelRequest4Web) (model.ReportStatusModel, error) {
+	organizationID, err := currentOrganization(ctx)
+	if err != nil {
+		return model.ReportStatusModel{}, err
+	}
+
+	statusModel := model.ReportStatusModel{
+		OrganizationID: organizationID,
+		Statuses:       req.Statuses,
+		UpdatedBy:      helper.GetUserID(ctx),
+	}
+	if err := workflow.CheckStatusModel(statusModel); err != nil {
+		return model.ReportStatusModel{}, err
+	}
+	workflow.SortStatuses(&statusModel)
+
+	if err := s.repo.Upsert(ctx, &statusModel); err != nil {
+		return model.ReportStatusModel{}, err
+	}
+	return statusModel, nil
+}
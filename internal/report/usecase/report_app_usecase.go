@@ -8,28 +8,41 @@ import (
 	"report-service/internal/gateway"
 	"report-service/internal/report/dto/request"
 	"report-service/internal/report/dto/response"
+	"report-service/internal/report/event"
 	"report-service/internal/report/mapper"
 	"report-service/internal/report/model"
 	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/workflow"
 	"report-service/pkg/constants"
+	"sort"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportAppUseCase interface {
 	GetReport4App(ctx context.Context, req *request.GetReportRequest4App) (response.ReportResponse, error)
-	UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) error
+	UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) (*response.UploadReportResponse, error)
 	GetTeacherReportTasks4App(ctx context.Context) ([]response.GetTeacherReportTasksResponse4App, error)
+	UpdateEditingLock4App(ctx context.Context, req request.EditingLockRequest4App, action constants.EditingLockAction) (*model.EditingLock, error)
+	GetSyncChanges4App(ctx context.Context, req request.SyncChangesRequest4App) (*response.SyncChangesResponse4App, error)
+	PushSync4App(ctx context.Context, req request.SyncPushRequest4App) (*response.SyncPushResponse4App, error)
 }
 
 type reportAppUseCase struct {
-	reportRepo  repository.ReportRepository
-	historyRepo repository.ReportHistoryRepository
-	userGw      gateway.UserGateway
-	classroomGw gateway.ClassroomGateway
-	termGw      gateway.TermGateway
-	mediaGw     gateway.MediaGateway
+	reportRepo       repository.ReportRepository
+	historyRepo      repository.ReportHistoryRepository
+	userGw           gateway.UserGateway
+	classroomGw      gateway.ClassroomGateway
+	termGw           gateway.TermGateway
+	mediaGw          gateway.MediaGateway
+	broker           event.Broker
+	schemas          schema.Registry
+	deadlineRepo     repository.ReportDeadlineRepository
+	statusChangeRepo repository.ReportStatusChangeRepository
+	statusModelRepo  repository.ReportStatusModelRepository
 }
 
 func NewReportAppUseCase(
@@ -39,14 +52,24 @@ func NewReportAppUseCase(
 	classroomGw gateway.ClassroomGateway,
 	termGw gateway.TermGateway,
 	mediaGw gateway.MediaGateway,
+	broker event.Broker,
+	schemas schema.Registry,
+	deadlineRepo repository.ReportDeadlineRepository,
+	statusChangeRepo repository.ReportStatusChangeRepository,
+	statusModelRepo repository.ReportStatusModelRepository,
 ) ReportAppUseCase {
 	return &reportAppUseCase{
-		reportRepo:  reportRepo,
-		historyRepo: historyRepo,
-		userGw:      userGw,
-		classroomGw: classroomGw,
-		termGw:      termGw,
-		mediaGw:     mediaGw,
+		reportRepo:       reportRepo,
+		historyRepo:      historyRepo,
+		userGw:           userGw,
+		classroomGw:      classroomGw,
+		termGw:           termGw,
+		mediaGw:          mediaGw,
+		broker:           broker,
+		schemas:          schemas,
+		deadlineRepo:     deadlineRepo,
+		statusChangeRepo: statusChangeRepo,
+		statusModelRepo:  statusModelRepo,
 	}
 }
 
@@ -96,30 +119,60 @@ func (u *reportAppUseCase) GetReport4App(ctx context.Context, req *request.GetRe
 
 	}
 
-	return mapper.MapReportToResDTO(report, nil, managerCommentPreviousTerm, teacherReportPrevioiusTerm, ""), nil
+	res := mapper.MapReportToResDTO(report, nil, managerCommentPreviousTerm, teacherReportPrevioiusTerm, "")
+
+	customSections, err := u.schemas.CustomSections(ctx, student.OrganizationID)
+	if err != nil {
+		return response.ReportResponse{}, err
+	}
+	mapper.MapCustomSections(&res, customSections)
+
+	return res, nil
 }
 
-func (u *reportAppUseCase) UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) error {
+func (u *reportAppUseCase) UploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) (*response.UploadReportResponse, error) {
+	report, err := u.uploadReport4App(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &response.UploadReportResponse{Version: report.Version}, nil
+}
+
+// uploadReport4App lưu report từ app, trả về report sau khi lưu (có ID + version mới)
+func (u *reportAppUseCase) uploadReport4App(ctx context.Context, req *request.UploadReport4AppRequest) (*model.Report, error) {
 	// get student info
 	student, _ := u.userGw.GetStudentInfo(ctx, req.StudentID)
 	if student == nil {
-		return errors.New("student not found")
+		return nil, errors.New("student not found")
 	}
 
 	// get teacher by usser id and organization if of student
 	editorID := helper.GetUserID(ctx)
 	teacher, _ := u.userGw.GetTeacherInfo(ctx, editorID, student.OrganizationID)
 	if teacher == nil {
-		return errors.New("teacher not found")
+		return nil, errors.New("teacher not found")
+	}
+
+	// app có thể gửi status theo tên organization đặt
+	statusModel, err := loadStatusModel(ctx, u.statusModelRepo, student.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	workflow.NewStatusNames(statusModel).Normalize(req.ReportData)
+
+	if err := u.schemas.Validate(ctx, student.OrganizationID, req.ReportData); err != nil {
+		return nil, err
 	}
 
 	report := &model.Report{
-		StudentID:  req.StudentID,
-		TopicID:    req.TopicID,
-		TermID:     req.TermID,
-		Language:   req.Language,
-		Status:     req.Status,
-		ReportData: req.ReportData,
+		StudentID:       req.StudentID,
+		TopicID:         req.TopicID,
+		TermID:          req.TermID,
+		Language:        req.Language,
+		Status:          req.Status,
+		ReportData:      req.ReportData,
+		ExpectedVersion: req.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientApp),
 	}
 
 	if editorID != "" {
@@ -127,27 +180,40 @@ func (u *reportAppUseCase) UploadReport4App(ctx context.Context, req *request.Up
 	}
 
 	// create or update report
-	err := u.reportRepo.CreateOrUpdateStudentView4App(ctx, report)
+	transitions, err := saveWithMerge(ctx, u.reportRepo, u.historyRepo, report, u.reportRepo.CreateOrUpdateStudentView4App)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// save report history
 	history := &model.ReportHistory{
-		ID:         primitive.NewObjectID(),
-		ReportID:   report.ID,
-		EditorID:   report.EditorID,
-		Type:       string(constants.ReportHistoryTypeAppStudentView),
-		EditorRole: string(constants.ReportHistoryRoleTeacher),
-		Report:     report,
-		Timestamp:  time.Now(),
+		ID:          primitive.NewObjectID(),
+		ReportID:    report.ID,
+		EditorID:    report.EditorID,
+		ClassroomID: req.ClassroomID,
+		Type:        string(constants.ReportHistoryTypeAppStudentView),
+		EditorRole:  string(constants.ReportHistoryRoleTeacher),
+		Report:      report,
+		Transitions: transitions,
+		Timestamp:   time.Now(),
+	}
+
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
+		return nil, err
 	}
 
-	if err := u.historyRepo.Create(ctx, history); err != nil {
-		return err
+	// teacher nộp lại section bị trả về → đóng rejection
+	var resolved []string
+	for _, t := range transitions {
+		if t.From == string(constants.SectionStatusTeacher) {
+			resolved = append(resolved, t.Section)
+		}
+	}
+	if err := u.reportRepo.ResolveRejections(ctx, report.ID, resolved); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return report, nil
 }
 
 func (u *reportAppUseCase) GetTeacherReportTasks4App(ctx context.Context) ([]response.GetTeacherReportTasksResponse4App, error) {
@@ -159,56 +225,227 @@ func (u *reportAppUseCase) GetTeacherReportTasks4App(ctx context.Context) ([]res
 	}
 
 	var results []response.GetTeacherReportTasksResponse4App
+	customSectionsByOrg := make(map[string][]*model.ReportSectionDefinition)
+	deadlinesByOrgTerm := make(map[string][]*model.ReportDeadline)
+	now := time.Now()
 
 	for _, r := range reports {
 
-		if r.ReportData != nil {
-			reportData := helper.ToBsonM(r.ReportData)
-			for key, val := range reportData {
-				section := helper.ToBsonM(val)
-				status, _ := section["status"].(string)
-
-				if status == "teacher" || status == "empty" {
-					termTitle := ""
-					topicTitle := ""
-					stdName := ""
-					term, _ := u.termGw.GetTermByID(ctx, r.TermID)
-					topic, _ := u.mediaGw.GetTopicByID(ctx, r.TopicID)
-					student, _ := u.userGw.GetStudentInfo(ctx, r.StudentID)
-
-					if term != nil {
-						termTitle = term.Title
-					}
-					if topic != nil {
-						topicTitle = topic.Title
-					}
-					if student != nil {
-						stdName = student.Name
-					}
-
-					languageTask := ""
-					if r.Language == "english-united_kingdom" {
-						languageTask = "🇺🇸 English 🇬🇧 United Kingdom"
-					}
-
-					if r.Language == "vietnamese-ho_chi_minh" {
-						languageTask = "🇻🇳 Vietnamese 🇻🇳 Ho Chi Minh"
-					}
-
-					results = append(results, response.GetTeacherReportTasksResponse4App{
-						Term:        termTitle,
-						Topic:       topicTitle,
-						StudentName: stdName,
-						Deadline:    "empty",
-						Task:        constants.TeacherReportTask(key),
-						Status:      status,
-						Language:    languageTask,
-					})
+		termTitle := ""
+		topicTitle := ""
+		stdName := ""
+		term, _ := u.termGw.GetTermByID(ctx, r.TermID)
+		topic, _ := u.mediaGw.GetTopicByID(ctx, r.TopicID)
+		student, _ := u.userGw.GetStudentInfo(ctx, r.StudentID)
+
+		if term != nil {
+			termTitle = term.Title
+		}
+		if topic != nil {
+			topicTitle = topic.Title
+		}
+
+		// section tuỳ biến của organization, chưa nhập gì vẫn là task "empty"
+		var customSections []*model.ReportSectionDefinition
+		var deadlines []*model.ReportDeadline
+		if student != nil {
+			stdName = student.Name
+			if defs, ok := customSectionsByOrg[student.OrganizationID]; ok {
+				customSections = defs
+			} else {
+				customSections, err = u.schemas.CustomSections(ctx, student.OrganizationID)
+				if err != nil {
+					return nil, fmt.Errorf("get custom sections failed: %w", err)
+				}
+				customSectionsByOrg[student.OrganizationID] = customSections
+			}
+
+			orgTerm := student.OrganizationID + "|" + r.TermID
+			if cached, ok := deadlinesByOrgTerm[orgTerm]; ok {
+				deadlines = cached
+			} else {
+				deadlines, err = u.deadlineRepo.GetByOrganization(ctx, student.OrganizationID, r.TermID)
+				if err != nil {
+					return nil, fmt.Errorf("get deadlines failed: %w", err)
+				}
+				deadlinesByOrgTerm[orgTerm] = deadlines
+			}
+		}
+
+		languageTask := ""
+		if r.Language == "english-united_kingdom" {
+			languageTask = "🇺🇸 English 🇬🇧 United Kingdom"
+		}
+
+		if r.Language == "vietnamese-ho_chi_minh" {
+			languageTask = "🇻🇳 Vietnamese 🇻🇳 Ho Chi Minh"
+		}
+
+		reportData := helper.ToBsonM(r.ReportData)
+		taskTitles := make(map[string]string, len(customSections))
+		for _, def := range customSections {
+			taskTitles[def.Key] = def.Title
+			if _, ok := reportData[def.Key]; !ok {
+				reportData[def.Key] = bson.M{"status": string(constants.SectionStatusEmpty)}
+			}
+		}
+
+		for key, val := range reportData {
+			section := helper.ToBsonM(val)
+			status, _ := section["status"].(string)
+
+			if status == "teacher" || status == "empty" {
+				returned := false
+				returnReason := ""
+				if rejection := openRejection(r.Rejections, key); rejection != nil {
+					returned = true
+					returnReason = rejection.Reason
+				}
+
+				task := response.GetTeacherReportTasksResponse4App{
+					Term:         termTitle,
+					Topic:        topicTitle,
+					StudentName:  stdName,
+					Deadline:     "empty",
+					Task:         constants.TeacherReportTask(key),
+					TaskTitle:    taskTitles[key],
+					Status:       status,
+					Language:     languageTask,
+					Returned:     returned,
+					ReturnReason: returnReason,
 				}
+				if deadline := model.ResolveDeadline(deadlines, r.TopicID, key); deadline != nil {
+					dueAt := deadline.DueAt
+					task.Deadline = dueAt.Format(time.RFC3339)
+					task.DueAt = &dueAt
+					task.Overdue = now.After(dueAt)
+				}
+
+				results = append(results, task)
 			}
 		}
 
 	}
 
+	sortTasksByUrgency(results)
+
 	return results, nil
 }
+
+// sortTasksByUrgency: hạn gần nhất (kể cả quá hạn) lên đầu, task không có deadline xuống cuối
+func sortTasksByUrgency(tasks []response.GetTeacherReportTasksResponse4App) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i].DueAt, tasks[j].DueAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+}
+
+// openRejection trả về lần trả về gần nhất chưa được xử lý của section
+func openRejection(rejections []model.SectionRejection, section string) *model.SectionRejection {
+	var latest *model.SectionRejection
+	for i := range rejections {
+		rj := &rejections[i]
+		if rj.Section != section || rj.ResolvedAt != nil {
+			continue
+		}
+		if latest == nil || rj.RejectedAt.After(latest.RejectedAt) {
+			latest = rj
+		}
+	}
+	return latest
+}
+
+func (u *reportAppUseCase) UpdateEditingLock4App(ctx context.Context, req request.EditingLockRequest4App, action constants.EditingLockAction) (*model.EditingLock, error) {
+	report, err := u.reportRepo.GetByStudentTopicTermAndLanguage(ctx, req.StudentID, req.TopicID, req.TermID, req.Language)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateEditingLock(ctx, u.reportRepo, u.broker, report, constants.EditingClientApp, action)
+}
+
+// GetSyncChanges4App trả các report (kể cả comment của manager) của teacher hiện tại thay đổi sau sync token
+func (u *reportAppUseCase) GetSyncChanges4App(ctx context.Context, req request.SyncChangesRequest4App) (*response.SyncChangesResponse4App, error) {
+	editorID := helper.GetUserID(ctx)
+	if editorID == "" {
+		return nil, errors.New("user not found")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	reports, syncToken, hasMore, err := u.reportRepo.GetChangedByEditor(ctx, editorID, req.SyncToken, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &response.SyncChangesResponse4App{
+		Reports:   make([]response.ReportResponse, 0, len(reports)),
+		SyncToken: syncToken,
+		HasMore:   hasMore,
+	}
+	for _, report := range reports {
+		res.Reports = append(res.Reports, mapper.MapReportToResDTO(report, nil, response.ManagerCommentPreviousTerm{}, response.TeacherReportPreviousTerm{}, ""))
+	}
+
+	return res, nil
+}
+
+// PushSync4App áp dụng lần lượt các chỉnh sửa offline, item lỗi không chặn các item sau
+func (u *reportAppUseCase) PushSync4App(ctx context.Context, req request.SyncPushRequest4App) (*response.SyncPushResponse4App, error) {
+	res := &response.SyncPushResponse4App{
+		Results: make([]response.SyncPushItemResult4App, 0, len(req.Items)),
+	}
+	// các item thường cùng term, history của cả batch dùng chung một lần tra lớp
+	ctx = withClassroomLookup(ctx)
+
+	for i := range req.Items {
+		item := req.Items[i]
+		result := response.SyncPushItemResult4App{
+			Index:     i,
+			StudentID: item.StudentID,
+			TopicID:   item.TopicID,
+			TermID:    item.TermID,
+			Language:  item.Language,
+		}
+
+		report, err := u.uploadReport4App(ctx, &item)
+		if err == nil {
+			result.Result = constants.SyncPushApplied
+			result.ReportID = report.ID.Hex()
+			result.Version = report.Version
+			res.Applied++
+			res.Results = append(res.Results, result)
+			continue
+		}
+
+		result.Error = err.Error()
+		var conflict *repository.VersionConflictError
+		var locked *repository.LockHeldError
+		switch {
+		case errors.As(err, &conflict):
+			result.Result = constants.SyncPushConflict
+			result.CurrentVersion = &conflict.CurrentVersion
+			result.Conflicts = conflict.Conflicts
+			if !conflict.ReportID.IsZero() {
+				result.ReportID = conflict.ReportID.Hex()
+			}
+			res.Conflicts++
+		case errors.As(err, &locked):
+			result.Result = constants.SyncPushLocked
+			result.EditingLock = locked.Lock
+			res.Conflicts++
+		default:
+			result.Result = constants.SyncPushFailed
+			res.Failed++
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"report-service/helper"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/event"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/pkg/constants"
+)
+
+// editingWriter: thông tin user hiện tại trên client (app/web) để kiểm tra / giữ editing lock
+func editingWriter(ctx context.Context, client constants.EditingClient) *model.EditingLock {
+	writer := &model.EditingLock{
+		HolderID: helper.GetUserID(ctx),
+		Client:   string(client),
+	}
+
+	if currentUser, ok := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser); ok && currentUser != nil {
+		if writer.HolderID == "" {
+			writer.HolderID = currentUser.ID
+		}
+		writer.HolderName = currentUser.Fullname
+		if writer.HolderName == "" {
+			writer.HolderName = currentUser.Nickname
+		}
+	}
+
+	return writer
+}
+
+// updateEditingLock acquire / renew / release lock chỉnh sửa của report cho user hiện tại
+func updateEditingLock(ctx context.Context, reportRepo repository.ReportRepository, broker event.Broker, report *model.Report, client constants.EditingClient, action constants.EditingLockAction) (*model.EditingLock, error) {
+	writer := editingWriter(ctx, client)
+	if writer.HolderID == "" {
+		return nil, errors.New("user not found")
+	}
+
+	var lock *model.EditingLock
+	var err error
+	switch action {
+	case constants.EditingLockAcquire:
+		lock, err = reportRepo.AcquireEditingLock(ctx, report.ID, *writer, constants.EditingLockTTL)
+	case constants.EditingLockRenew:
+		lock, err = reportRepo.RenewEditingLock(ctx, report.ID, *writer, constants.EditingLockTTL)
+	case constants.EditingLockRelease:
+		err = reportRepo.ReleaseEditingLock(ctx, report.ID, *writer)
+	default:
+		return nil, errors.New("invalid editing lock action")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// renew chỉ gia hạn, holder không đổi nên không cần báo
+	if action != constants.EditingLockRenew {
+		publishEvent(ctx, broker, event.FromLock(report, lock))
+	}
+	return lock, nil
+}
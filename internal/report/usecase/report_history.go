@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"report-service/internal/gateway"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/event"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+	"report-service/logger"
+	"report-service/pkg/constants"
+	"sync"
+	"time"
+)
+
+// saveHistory lưu history kèm snapshot đầy đủ của report sau khi save, ghi lại các lần đổi status
+// rồi đẩy event cho client đang theo dõi
+func saveHistory(ctx context.Context, historyRepo repository.ReportHistoryRepository, statusChangeRepo repository.ReportStatusChangeRepository, classroomGw gateway.ClassroomGateway, broker event.Broker, history *model.ReportHistory) error {
+	// snapshot là document trả về từ chính lần ghi, không đọc lại để khỏi lấy nhầm version của lần ghi chen vào
+	if history.Report != nil && history.Report.Saved != nil {
+		history.Report = history.Report.Saved
+	}
+	if history.Report != nil {
+		history.Version = history.Report.Version
+		if history.StudentID == "" {
+			history.StudentID = history.Report.StudentID
+		}
+	}
+	stampHistoryScope(ctx, classroomGw, history)
+	if err := historyRepo.Create(ctx, history); err != nil {
+		return err
+	}
+
+	recordStatusChanges(ctx, statusChangeRepo, history)
+	publishEvent(ctx, broker, event.FromHistory(history))
+	return nil
+}
+
+// stampHistoryScope gắn organization và lớp của học sinh cho mọi history để search lọc được,
+// không tìm được lớp thì chỉ ghi log
+func stampHistoryScope(ctx context.Context, classroomGw gateway.ClassroomGateway, history *model.ReportHistory) {
+	if history.OrganizationID == "" {
+		if currentUser, ok := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser); ok && currentUser != nil {
+			if currentUser.OrganizationAdmin != nil {
+				history.OrganizationID = currentUser.OrganizationAdmin.ID
+			} else {
+				history.OrganizationID = currentUser.OrganizationIdActive
+			}
+		}
+	}
+
+	// caller đã biết lớp (classroom view, accept, client gửi kèm) thì không tra qua classroom service
+	if history.ClassroomID != "" || history.Report == nil || classroomGw == nil {
+		return
+	}
+	classroomID, err := findClassroom(ctx, classroomGw, history.Report.TermID, history.Report.StudentID)
+	if err != nil {
+		logger.WriteLogEx("warn", "get classroom of history failed: "+err.Error(), map[string]interface{}{
+			"report_id": history.ReportID.Hex(),
+		})
+		return
+	}
+	history.ClassroomID = classroomID
+}
+
+// classroomLookup: lớp của học sinh theo term (term → student → classroom), dùng chung trong một request
+type classroomLookup struct {
+	mu     sync.Mutex
+	byTerm map[string]map[string]string
+}
+
+type classroomLookupKey struct{}
+
+// withClassroomLookup gắn cache tra lớp vào ctx cho request ghi nhiều report (sync push),
+// mỗi term chỉ gọi classroom service một lần
+func withClassroomLookup(ctx context.Context) context.Context {
+	return context.WithValue(ctx, classroomLookupKey{}, &classroomLookup{byTerm: make(map[string]map[string]string)})
+}
+
+// findClassroom tìm lớp của học sinh trong term, rỗng nếu học sinh chưa được xếp lớp
+func findClassroom(ctx context.Context, classroomGw gateway.ClassroomGateway, termID, studentID string) (string, error) {
+	lookup, _ := ctx.Value(classroomLookupKey{}).(*classroomLookup)
+	if lookup != nil {
+		lookup.mu.Lock()
+		defer lookup.mu.Unlock()
+		if students, ok := lookup.byTerm[termID]; ok {
+			return students[studentID], nil
+		}
+	}
+
+	classrooms, err := classroomGw.GetAllClassroomAssignTemplate(ctx, termID)
+	if err != nil {
+		return "", err
+	}
+	students := make(map[string]string)
+	for _, classroom := range classrooms {
+		if classroom == nil {
+			continue
+		}
+		for _, at := range classroom.AssignTemplates {
+			if _, ok := students[at.StudentID]; !ok {
+				students[at.StudentID] = classroom.ClassroomID
+			}
+		}
+	}
+
+	if lookup != nil {
+		lookup.byTerm[termID] = students
+	}
+	return students[studentID], nil
+}
+
+// recordStatusChanges: lỗi ghi chỉ ảnh hưởng thống kê, không làm fail thao tác lưu report
+func recordStatusChanges(ctx context.Context, repo repository.ReportStatusChangeRepository, history *model.ReportHistory) {
+	if repo == nil || history.Report == nil || len(history.Transitions) == 0 {
+		return
+	}
+
+	changedAt := history.Timestamp
+	if changedAt.IsZero() {
+		changedAt = time.Now()
+	}
+
+	changes := make([]*model.ReportStatusChange, 0, len(history.Transitions))
+	for _, t := range history.Transitions {
+		changes = append(changes, &model.ReportStatusChange{
+			ReportID:       history.ReportID,
+			OrganizationID: history.OrganizationID,
+			TermID:         history.Report.TermID,
+			TopicID:        history.Report.TopicID,
+			StudentID:      history.Report.StudentID,
+			EditorID:       history.Report.EditorID,
+			Language:       history.Report.Language,
+			Section:        t.Section,
+			From:           t.From,
+			To:             t.To,
+			Role:           t.Role,
+			ChangedBy:      history.EditorID,
+			ChangedAt:      changedAt,
+		})
+	}
+
+	if err := repo.CreateMany(ctx, changes); err != nil {
+		logger.WriteLogEx("error", "record status changes failed: "+err.Error(), map[string]interface{}{
+			"report_id": history.ReportID.Hex(),
+		})
+	}
+}
+
+// publishEvent: lỗi publish chỉ ghi log, không làm fail thao tác lưu report
+func publishEvent(ctx context.Context, broker event.Broker, e event.ReportEvent) {
+	if broker == nil {
+		return
+	}
+	if err := broker.Publish(ctx, e); err != nil {
+		logger.WriteLogEx("error", "publish report event failed: "+err.Error(), map[string]interface{}{
+			"report_id": e.ReportID,
+			"type":      e.Type,
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"report-service/internal/report/diff"
+	"report-service/internal/report/model"
+	"report-service/internal/report/repository"
+)
+
+// số lần merge + lưu lại tối đa khi report liên tục bị ghi bởi người khác
+const maxMergeAttempts = 3
+
+type saveReportFunc func(ctx context.Context, report *model.Report) ([]model.SectionTransition, error)
+
+// saveWithMerge lưu report, nếu version client gửi đã cũ thì merge 3-way với snapshot của version đó
+// (lấy từ history) rồi lưu lại. Chỉ trả conflict khi cùng field bị sửa ở cả 2 phía.
+func saveWithMerge(ctx context.Context, reportRepo repository.ReportRepository, historyRepo repository.ReportHistoryRepository, report *model.Report, save saveReportFunc) ([]model.SectionTransition, error) {
+	for attempt := 1; ; attempt++ {
+		transitions, err := save(ctx, report)
+
+		var conflict *repository.VersionConflictError
+		if err == nil || report.ExpectedVersion == nil || !errors.As(err, &conflict) || conflict.ReportID.IsZero() || attempt >= maxMergeAttempts {
+			return transitions, err
+		}
+
+		// không có snapshot của base version (report cũ chưa có version) thì không merge được
+		base, herr := historyRepo.GetByReportVersion(ctx, conflict.ReportID, *report.ExpectedVersion)
+		if herr != nil || base.Report == nil {
+			return nil, err
+		}
+		current, rerr := reportRepo.GetByID(ctx, conflict.ReportID.Hex())
+		if rerr != nil {
+			return nil, err
+		}
+
+		status, data, conflicts := diff.MergeReport(base.Report, current, report)
+		if len(conflicts) > 0 {
+			return nil, &repository.VersionConflictError{
+				ReportID:       current.ID,
+				CurrentVersion: current.Version,
+				Conflicts:      conflicts,
+			}
+		}
+
+		version := current.Version
+		report.Status = status
+		report.ReportData = data
+		report.ExpectedVersion = &version
+	}
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"report-service/helper"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/dto/response"
+	"report-service/internal/report/model"
+	"report-service/internal/report/workflow"
+	"report-service/pkg/constants"
+	"sort"
+	"time"
+)
+
+// GetReviewQueue4Web liệt kê các section đang chờ manager trong các lớp của term:
+// section "done" chờ accept, hoặc teacher sửa lại sau lần comment gần nhất của manager
+func (u *reportWebUsecase) GetReviewQueue4Web(ctx context.Context, req request.GetReviewQueueRequest4Web) (*response.ReviewQueueResponse4Web, error) {
+	classrooms, err := u.classroomGw.GetAllClassroomAssignTemplate(ctx, req.TermID)
+	if err != nil {
+		return nil, err
+	}
+
+	newestFirst := constants.ReviewQueueSort(req.Sort) == constants.ReviewQueueSortNewest
+	now := time.Now()
+
+	res := &response.ReviewQueueResponse4Web{
+		TermID:     req.TermID,
+		Classrooms: make([]response.ReviewQueueClassroom4Web, 0),
+	}
+
+	for _, class := range classrooms {
+		if class == nil {
+			continue
+		}
+
+		group := response.ReviewQueueClassroom4Web{
+			ClassroomID:   class.ClassroomID,
+			ClassroomName: class.ClassroomName,
+			Items:         make([]response.ReviewQueueItem4Web, 0),
+		}
+
+		for _, assign := range class.AssignTemplates {
+			editor, err := u.userGw.GetUserByTeacher(ctx, assign.TeacherID)
+			if err != nil || editor == nil {
+				continue
+			}
+
+			reports, err := u.reportRepo.GetByEditorIDAndStudentIDAndTermID(ctx, editor.ID, assign.StudentID, req.TermID)
+			if err != nil {
+				return nil, err
+			}
+
+			var items []response.ReviewQueueItem4Web
+			for _, r := range reports {
+				if req.UniqueLangKey != "" && r.Language != req.UniqueLangKey {
+					continue
+				}
+				items = append(items, reviewQueueItems(r, now)...)
+			}
+			if len(items) == 0 {
+				continue
+			}
+
+			// chỉ lấy tên khi có section cần xử lý
+			studentName, teacherName := "", ""
+			if student, _ := u.userGw.GetStudentInfo(ctx, assign.StudentID); student != nil {
+				studentName = student.Name
+			}
+			if teacher, _ := u.userGw.GetTeacherById(ctx, assign.TeacherID); teacher != nil {
+				teacherName = teacher.Name
+			}
+			for i := range items {
+				items[i].StudentName = studentName
+				items[i].TeacherID = assign.TeacherID
+				items[i].TeacherName = teacherName
+			}
+
+			group.Items = append(group.Items, items...)
+		}
+
+		if len(group.Items) == 0 {
+			continue
+		}
+
+		sortReviewQueueItems(group.Items, newestFirst)
+		oldest := group.Items[0].WaitingSince
+		for _, item := range group.Items {
+			if item.WaitingSince.Before(oldest) {
+				oldest = item.WaitingSince
+			}
+		}
+		group.OldestWaiting = &oldest
+
+		res.Total += len(group.Items)
+		res.Classrooms = append(res.Classrooms, group)
+	}
+
+	// lớp có section chờ lâu nhất (hoặc mới nhất) lên đầu
+	sort.SliceStable(res.Classrooms, func(i, j int) bool {
+		a, b := res.Classrooms[i].Items[0].WaitingSince, res.Classrooms[j].Items[0].WaitingSince
+		if newestFirst {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+
+	return res, nil
+}
+
+// reviewQueueItems trả về các section của report đang chờ manager
+func reviewQueueItems(r *model.Report, now time.Time) []response.ReviewQueueItem4Web {
+	var items []response.ReviewQueueItem4Web
+
+	for section, data := range r.ReportData {
+		fields := helper.ToBsonM(data)
+		raw, ok := fields["status"].(string)
+		if !ok {
+			continue
+		}
+		status, ok := workflow.ParseStatus(raw)
+		if !ok || status == constants.SectionStatusAccepted {
+			continue
+		}
+
+		updatedAtStr, _ := fields["updated_at"].(string)
+		managerUpdatedAtStr, _ := fields["manager_updated_at"].(string)
+		updatedAt := helper.ParseTimeStr(updatedAtStr)
+		managerUpdatedAt := helper.ParseTimeStr(managerUpdatedAtStr)
+
+		var reason constants.ReviewQueueReason
+		switch {
+		case status == constants.SectionStatusDone:
+			reason = constants.ReviewQueueAwaitingAcceptance
+		case !managerUpdatedAt.IsZero() && updatedAt.After(managerUpdatedAt):
+			reason = constants.ReviewQueueUpdatedAfterComment
+		default:
+			continue
+		}
+
+		// section không có updated_at hợp lệ thì lấy thời điểm report được cập nhật
+		waitingSince := updatedAt
+		if waitingSince.IsZero() {
+			waitingSince = r.UpdatedAt
+		}
+
+		items = append(items, response.ReviewQueueItem4Web{
+			ReportID:       r.ID.Hex(),
+			StudentID:      r.StudentID,
+			TopicID:        r.TopicID,
+			Language:       r.Language,
+			Section:        section,
+			Status:         string(status),
+			Reason:         string(reason),
+			WaitingSince:   waitingSince,
+			WaitingMinutes: int64(now.Sub(waitingSince).Minutes()),
+		})
+	}
+
+	return items
+}
+
+func sortReviewQueueItems(items []response.ReviewQueueItem4Web, newestFirst bool) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.WaitingSince.Equal(b.WaitingSince) {
+			if newestFirst {
+				return a.WaitingSince.After(b.WaitingSince)
+			}
+			return a.WaitingSince.Before(b.WaitingSince)
+		}
+		if a.ReportID != b.ReportID {
+			return a.ReportID < b.ReportID
+		}
+		return a.Section < b.Section
+	})
+}
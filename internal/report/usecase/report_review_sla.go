@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	gw_response "report-service/internal/gateway/dto/response"
+	"report-service/internal/report/analytics"
+	"report-service/internal/report/dto/request"
+	"report-service/internal/report/dto/response"
+	"report-service/internal/report/mapper"
+	"report-service/pkg/constants"
+	"sort"
+	"time"
+)
+
+type slaAssignment struct {
+	classroomID   string
+	classroomName string
+	teacherID     string
+}
+
+// GetReviewSLA4Web thống kê thời gian từ lúc teacher nộp đến lúc manager accept section trong term,
+// theo lớp, teacher và topic. Chỉ tính report của học sinh đang được phân công trong các lớp của term.
+func (u *reportWebUsecase) GetReviewSLA4Web(ctx context.Context, req request.GetReviewSLARequest4Web) (*response.ReviewSLAResponse4Web, error) {
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil || currentUser.OrganizationAdmin == nil {
+		return nil, ErrNoOrganization
+	}
+
+	classrooms, err := u.classroomGw.GetAllClassroomAssignTemplate(ctx, req.TermID)
+	if err != nil {
+		return nil, err
+	}
+
+	// report được nhận diện qua editor (user id của teacher) + student
+	assignments := make(map[string]slaAssignment)
+	editorIDs := make(map[string]string)
+	classroomNames := make(map[string]string)
+	for _, class := range classrooms {
+		if class == nil {
+			continue
+		}
+		classroomNames[class.ClassroomID] = class.ClassroomName
+		for _, assign := range class.AssignTemplates {
+			editorID, ok := editorIDs[assign.TeacherID]
+			if !ok {
+				if editor, err := u.userGw.GetUserByTeacher(ctx, assign.TeacherID); err == nil && editor != nil {
+					editorID = editor.ID
+				}
+				editorIDs[assign.TeacherID] = editorID
+			}
+			if editorID == "" {
+				continue
+			}
+			assignments[editorID+"|"+assign.StudentID] = slaAssignment{
+				classroomID:   class.ClassroomID,
+				classroomName: class.ClassroomName,
+				teacherID:     assign.TeacherID,
+			}
+		}
+	}
+
+	changes, err := u.statusChangeRepo.GetByTerm(ctx, currentUser.OrganizationAdmin.ID, req.TermID, req.UniqueLangKey)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := changes[:0]
+	for _, c := range changes {
+		if _, ok := assignments[c.EditorID+"|"+c.StudentID]; ok {
+			scoped = append(scoped, c)
+		}
+	}
+
+	var overall []time.Duration
+	byClassroom := make(map[string][]time.Duration)
+	byTeacher := make(map[string][]time.Duration)
+	byTopic := make(map[string][]time.Duration)
+	for _, cycle := range analytics.ReviewCycles(scoped) {
+		a := assignments[cycle.Acceptance.EditorID+"|"+cycle.Acceptance.StudentID]
+		d := cycle.Duration()
+
+		overall = append(overall, d)
+		byClassroom[a.classroomID] = append(byClassroom[a.classroomID], d)
+		byTeacher[a.teacherID] = append(byTeacher[a.teacherID], d)
+		byTopic[cycle.Acceptance.TopicID] = append(byTopic[cycle.Acceptance.TopicID], d)
+	}
+
+	res := &response.ReviewSLAResponse4Web{
+		TermID:       req.TermID,
+		Overall:      mapper.MapDurationStats(analytics.Summarize(overall)),
+		TimeInStatus: make(map[string]response.DurationStats4Web),
+	}
+	for status, durations := range analytics.TimeInStatus(scoped) {
+		res.TimeInStatus[status] = mapper.MapDurationStats(analytics.Summarize(durations))
+	}
+
+	res.Classrooms = slaGroups(byClassroom, func(id string) string {
+		return classroomNames[id]
+	})
+	res.Teachers = slaGroups(byTeacher, func(id string) string {
+		if teacher, _ := u.userGw.GetTeacherById(ctx, id); teacher != nil {
+			return teacher.Name
+		}
+		return ""
+	})
+	res.Topics = slaGroups(byTopic, func(id string) string {
+		if topic, _ := u.mediaGw.GetTopicByID(ctx, id); topic != nil {
+			return topic.Title
+		}
+		return ""
+	})
+
+	return res, nil
+}
+
+// slaGroups: nhóm chậm nhất (p90 lớn nhất) lên đầu
+func slaGroups(durations map[string][]time.Duration, name func(id string) string) []response.ReviewSLAGroup4Web {
+	groups := make([]response.ReviewSLAGroup4Web, 0, len(durations))
+	for id, ds := range durations {
+		groups = append(groups, response.ReviewSLAGroup4Web{
+			ID:    id,
+			Name:  name(id),
+			Stats: mapper.MapDurationStats(analytics.Summarize(ds)),
+		})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Stats.P90Minutes != groups[j].Stats.P90Minutes {
+			return groups[i].Stats.P90Minutes > groups[j].Stats.P90Minutes
+		}
+		return groups[i].ID < groups[j].ID
+	})
+	return groups
+}
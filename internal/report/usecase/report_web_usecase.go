@@ -10,28 +10,42 @@ import (
 	gw_response "report-service/internal/gateway/dto/response"
 	"report-service/internal/report/dto/request"
 	"report-service/internal/report/dto/response"
+	"report-service/internal/report/event"
 	"report-service/internal/report/mapper"
 	"report-service/internal/report/model"
 	"report-service/internal/report/repository"
+	"report-service/internal/report/schema"
+	"report-service/internal/report/workflow"
 	"report-service/pkg/constants"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type ReportWebUseCase interface {
 	GetReport4Web(ctx context.Context, req *request.GetReportRequest4Web) (response.ReportResponse, error)
-	UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) error
+	UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) (*response.UploadReportResponse, error)
 	UploadClassroomReport4Web(ctx context.Context, req request.UploadClassroomReport4WebRequest) error
 	GetClassroomReports4Web(ctx context.Context, req request.GetClassroomReportRequest4Web) (*response.GetClassroomReportResponse4Web, error)
 	GetReportOverViewAllClassroom4Web(ctx context.Context, req request.GetReportOverViewAllClassroomRequest) (*response.GetReportOverviewAllClassroomResponse4Web, error)
 	GetReportOverViewByClassroom4Web(ctx context.Context, req request.GetReportOverViewByClassroomRequest) (*response.GetReportOverviewByClassroomResponse4Web, error)
 	ApplyTopicPlanTemplateIsSchool2Report(ctx context.Context, req request.ApplyTemplateIsSchoolToReportRequest) error
 	ApplyTopicPlanTemplateIsClassroom2Report(ctx context.Context, req request.ApplyTemplateIsClassroomToReportRequest) error
+	SendBackReport4Web(ctx context.Context, req request.SendBackReportRequest4Web) error
+	AcceptClassroomReports4Web(ctx context.Context, req request.AcceptClassroomReportRequest4Web) (*response.AcceptClassroomReportResponse4Web, error)
+	RestoreReportFromHistory4Web(ctx context.Context, historyID string, req request.RestoreReportRequest4Web) error
+	UpdateEditingLock4Web(ctx context.Context, req request.EditingLockRequest4Web, action constants.EditingLockAction) (*model.EditingLock, error)
+	SubscribeClassroomEvents4Web(ctx context.Context, req request.ClassroomReportEventRequest4Web) (*event.Subscription, error)
+	GetReviewQueue4Web(ctx context.Context, req request.GetReviewQueueRequest4Web) (*response.ReviewQueueResponse4Web, error)
+	GetReviewSLA4Web(ctx context.Context, req request.GetReviewSLARequest4Web) (*response.ReviewSLAResponse4Web, error)
 }
 
+// ErrNoOrganization: user web không quản lý organization nào nên không xác định được schema / phạm vi dữ liệu
+var ErrNoOrganization = errors.New("current user has no organization")
+
 type reportWebUsecase struct {
 	reportRepo             repository.ReportRepository
 	historyRepo            repository.ReportHistoryRepository
@@ -41,6 +55,10 @@ type reportWebUsecase struct {
 	termGw                 gateway.TermGateway
 	mediaGw                gateway.MediaGateway
 	fileGw                 gateway.FileGateway
+	broker                 event.Broker
+	schemas                schema.Registry
+	statusModelRepo        repository.ReportStatusModelRepository
+	statusChangeRepo       repository.ReportStatusChangeRepository
 }
 
 func NewReportWebUsecase(
@@ -52,6 +70,10 @@ func NewReportWebUsecase(
 	termGw gateway.TermGateway,
 	mediaGw gateway.MediaGateway,
 	fileGw gateway.FileGateway,
+	broker event.Broker,
+	schemas schema.Registry,
+	statusModelRepo repository.ReportStatusModelRepository,
+	statusChangeRepo repository.ReportStatusChangeRepository,
 ) ReportWebUseCase {
 	return &reportWebUsecase{
 		reportRepo:             reportRepo,
@@ -62,44 +84,177 @@ func NewReportWebUsecase(
 		termGw:                 termGw,
 		mediaGw:                mediaGw,
 		fileGw:                 fileGw,
+		broker:                 broker,
+		schemas:                schemas,
+		statusModelRepo:        statusModelRepo,
+		statusChangeRepo:       statusChangeRepo,
 	}
 }
 
-func (u *reportWebUsecase) UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) error {
+func (u *reportWebUsecase) UploadReport4Web(ctx context.Context, req *request.UploadReport4AWebRequest) (*response.UploadReportResponse, error) {
+	if err := u.prepareReportData(ctx, req.ReportData); err != nil {
+		return nil, err
+	}
+
 	report := &model.Report{
-		StudentID:  req.StudentID,
-		TopicID:    req.TopicID,
-		TermID:     req.TermID,
-		Language:   req.UniqueLangKey,
-		Status:     req.Status,
-		Editing:    &req.Editing,
-		ReportData: req.ReportData,
+		StudentID:       req.StudentID,
+		TopicID:         req.TopicID,
+		TermID:          req.TermID,
+		Language:        req.UniqueLangKey,
+		Status:          req.Status,
+		ReportData:      req.ReportData,
+		ExpectedVersion: req.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientWeb),
 	}
 
 	// check report da duoc tao tu app chua ?
 	reportExist, _ := u.reportRepo.GetByStudentTopicTermAndLanguage(ctx, req.StudentID, req.TopicID, req.TermID, req.UniqueLangKey)
 	if reportExist == nil {
-		return errors.New("report not found, need to create report from teacher")
+		return nil, errors.New("report not found, need to create report from teacher")
 	}
 
 	// create or update report
-	err := u.reportRepo.CreateOrUpdateStudentView4Web(ctx, report)
+	transitions, err := saveWithMerge(ctx, u.reportRepo, u.historyRepo, report, u.reportRepo.CreateOrUpdateStudentView4Web)
+	if err != nil {
+		return nil, err
+	}
+
+	// save report history
+	history := &model.ReportHistory{
+		ID:          primitive.NewObjectID(),
+		ReportID:    report.ID,
+		EditorID:    helper.GetUserID(ctx),
+		ClassroomID: req.ClassroomID,
+		Type:        string(constants.ReportHistoryTypeWebStudentView),
+		EditorRole:  string(constants.ReportHistoryRoleManager),
+		Report:      report,
+		Transitions: transitions,
+		Timestamp:   time.Now(),
+	}
+
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
+		return nil, err
+	}
+
+	return &response.UploadReportResponse{Version: report.Version}, nil
+}
+
+func (u *reportWebUsecase) SendBackReport4Web(ctx context.Context, req request.SendBackReportRequest4Web) error {
+	editorID := helper.GetUserID(ctx)
+	now := time.Now()
+
+	report := &model.Report{
+		StudentID:       req.StudentID,
+		TopicID:         req.TopicID,
+		TermID:          req.TermID,
+		Language:        req.UniqueLangKey,
+		ExpectedVersion: req.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientWeb),
+	}
+
+	// section gửi trùng chỉ trả về một lần
+	seen := make(map[string]bool, len(req.Sections))
+	rejections := make([]model.SectionRejection, 0, len(req.Sections))
+	for _, section := range req.Sections {
+		if seen[section] {
+			continue
+		}
+		seen[section] = true
+		rejections = append(rejections, model.SectionRejection{
+			Section:    section,
+			Reason:     req.Reason,
+			RejectedBy: editorID,
+			RejectedAt: now,
+		})
+	}
+
+	transitions, err := u.reportRepo.SendBackSections(ctx, report, rejections)
 	if err != nil {
 		return err
 	}
 
 	// save report history
 	history := &model.ReportHistory{
-		ID:         primitive.NewObjectID(),
-		ReportID:   report.ID,
-		EditorID:   helper.GetUserID(ctx),
-		Type:       string(constants.ReportHistoryTypeWebStudentView),
-		EditorRole: string(constants.ReportHistoryRoleManager),
-		Report:     report,
-		Timestamp:  time.Now(),
+		ID:          primitive.NewObjectID(),
+		ReportID:    report.ID,
+		EditorID:    editorID,
+		ClassroomID: req.ClassroomID,
+		Type:        string(constants.ReportHistoryTypeWebSendBack),
+		EditorRole:  string(constants.ReportHistoryRoleManager),
+		Report:      report,
+		Transitions: transitions,
+		Timestamp:   now,
 	}
 
-	if err := u.historyRepo.Create(ctx, history); err != nil {
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *reportWebUsecase) RestoreReportFromHistory4Web(ctx context.Context, historyID string, req request.RestoreReportRequest4Web) error {
+	sourceID, err := primitive.ObjectIDFromHex(historyID)
+	if err != nil {
+		return errors.New("invalid history id")
+	}
+
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	if currentUser == nil || currentUser.OrganizationAdmin == nil {
+		return ErrNoOrganization
+	}
+
+	source, err := u.historyRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		return err
+	}
+	if source.Report == nil || source.ReportID.IsZero() {
+		return errors.New("history has no report snapshot")
+	}
+
+	// history / report của organization khác trả về như không tồn tại
+	current, err := u.reportRepo.GetByID(ctx, source.ReportID.Hex())
+	if err != nil {
+		return err
+	}
+	organizationID := current.OrganizationID
+	if organizationID == "" {
+		// report cũ chưa backfill organization_id
+		organizationID = source.OrganizationID
+	}
+	if organizationID != currentUser.OrganizationAdmin.ID ||
+		(source.OrganizationID != "" && source.OrganizationID != currentUser.OrganizationAdmin.ID) {
+		return mongo.ErrNoDocuments
+	}
+
+	report := &model.Report{
+		ID:              source.ReportID,
+		Status:          source.Report.Status,
+		ReportData:      source.Report.ReportData,
+		ExpectedVersion: req.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientWeb),
+	}
+
+	transitions, err := u.reportRepo.RestoreSnapshot(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	// save report history
+	history := &model.ReportHistory{
+		ID:              primitive.NewObjectID(),
+		ReportID:        source.ReportID,
+		ClassroomID:     source.ClassroomID,
+		EditorID:        helper.GetUserID(ctx),
+		Type:            string(constants.ReportHistoryTypeWebRestore),
+		EditorRole:      string(constants.ReportHistoryRoleManager),
+		Report:          report,
+		Transitions:     transitions,
+		SourceHistoryID: &sourceID,
+		Timestamp:       time.Now(),
+	}
+
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
 		return err
 	}
 
@@ -180,17 +335,29 @@ func (u *reportWebUsecase) GetReport4Web(ctx context.Context, req *request.GetRe
 
 	res := mapper.MapReportToResDTO(report, teacher, managerCommentPreviousTerm, teacherReportPrevioiusTerm, "")
 
+	customSections, err := u.schemas.CustomSections(ctx, student.OrganizationID)
+	if err != nil {
+		return response.ReportResponse{}, err
+	}
+	mapper.MapCustomSections(&res, customSections)
+
 	return res, nil
 }
 
 func (u *reportWebUsecase) UploadClassroomReport4Web(ctx context.Context, req request.UploadClassroomReport4WebRequest) error {
+	if err := u.prepareReportData(ctx, req.ReportData); err != nil {
+		return err
+	}
+
 	report := &model.Report{
-		StudentID:  req.StudentID,
-		TopicID:    req.TopicID,
-		TermID:     req.TermID,
-		Language:   req.UniqueLangKey,
-		Status:     req.Status,
-		ReportData: req.ReportData,
+		StudentID:       req.StudentID,
+		TopicID:         req.TopicID,
+		TermID:          req.TermID,
+		Language:        req.UniqueLangKey,
+		Status:          req.Status,
+		ReportData:      req.ReportData,
+		ExpectedVersion: req.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientWeb),
 	}
 
 	// check report da duoc tao tu app chua ?
@@ -205,7 +372,7 @@ func (u *reportWebUsecase) UploadClassroomReport4Web(ctx context.Context, req re
 	}
 
 	// create or update report
-	err := u.reportRepo.CreateOrUpdateClassroomView4Web(ctx, report)
+	transitions, err := saveWithMerge(ctx, u.reportRepo, u.historyRepo, report, u.reportRepo.CreateOrUpdateClassroomView4Web)
 	if err != nil {
 		return err
 	}
@@ -219,16 +386,156 @@ func (u *reportWebUsecase) UploadClassroomReport4Web(ctx context.Context, req re
 		Type:        string(constants.ReportHistoryTypeWebClassroomView),
 		EditorRole:  string(constants.ReportHistoryRoleManager),
 		Report:      report,
+		Transitions: transitions,
 		Timestamp:   time.Now(),
 	}
 
-	if err := u.historyRepo.Create(ctx, history); err != nil {
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// prepareReportData đổi tên status của organization manager đang quản lý về status của workflow
+// rồi kiểm tra report_data theo schema của organization đó
+func (u *reportWebUsecase) prepareReportData(ctx context.Context, data map[string]interface{}) error {
+	var organizationID string
+	if currentUser, ok := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser); ok && currentUser != nil {
+		if currentUser.OrganizationAdmin == nil {
+			return ErrNoOrganization
+		}
+		organizationID = currentUser.OrganizationAdmin.ID
+	}
+
+	statusModel, err := loadStatusModel(ctx, u.statusModelRepo, organizationID)
+	if err != nil {
+		return err
+	}
+	workflow.NewStatusNames(statusModel).Normalize(data)
+
+	return u.schemas.Validate(ctx, organizationID, data)
+}
+
+// ===================================================== AcceptClassroomReports4Web =====================================================//
+func (u *reportWebUsecase) AcceptClassroomReports4Web(ctx context.Context, req request.AcceptClassroomReportRequest4Web) (*response.AcceptClassroomReportResponse4Web, error) {
+	assigned, err := u.classroomGw.GetClassroomAssignTemplate(ctx, req.TermID, req.ClassroomID)
+	if err != nil || assigned == nil {
+		return nil, fmt.Errorf("cannot get classroom template: %v", err)
+	}
+
+	res := &response.AcceptClassroomReportResponse4Web{
+		Results: make([]response.AcceptStudentReportResult, 0, len(assigned.AssignTemplates)),
+	}
+
+	for _, at := range assigned.AssignTemplates {
+		result := u.acceptStudentReport(ctx, req, at)
+		switch constants.BulkResult(result.Result) {
+		case constants.BulkResultAccepted:
+			res.Accepted++
+		case constants.BulkResultSkipped:
+			res.Skipped++
+		default:
+			res.Failed++
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	return res, nil
+}
+
+func (u *reportWebUsecase) acceptStudentReport(ctx context.Context, req request.AcceptClassroomReportRequest4Web, at gw_response.AssignTemplate) response.AcceptStudentReportResult {
+	result := response.AcceptStudentReportResult{
+		StudentID: at.StudentID,
+		TeacherID: at.TeacherID,
+	}
+
+	skip := func(reason string) response.AcceptStudentReportResult {
+		result.Result = string(constants.BulkResultSkipped)
+		result.Reason = reason
+		return result
+	}
+
+	editor, _ := u.userGw.GetUserByTeacher(ctx, at.TeacherID)
+	if editor == nil {
+		result.Result = string(constants.BulkResultFailed)
+		result.Reason = "teacher not found"
+		return result
+	}
+
+	existing, _ := u.reportRepo.GetByStudentTopicTermLanguageAndEditor(ctx, at.StudentID, req.TopicID, req.TermID, req.UniqueLangKey, editor.ID)
+	if existing == nil {
+		return skip("report not found")
+	}
+	result.ReportID = existing.ID.Hex()
+
+	// accept từng section đã done, section chưa done thì bỏ qua và báo lại trong skipped_sections
+	reportData := bson.M{}
+	for _, section := range req.Sections {
+		status, _ := helper.ToBsonM(existing.ReportData[section])["status"].(string)
+		current, _ := workflow.ParseStatus(status)
+		switch current {
+		case constants.SectionStatusDone:
+			reportData[section] = map[string]interface{}{"status": string(constants.SectionStatusAccepted)}
+		case constants.SectionStatusAccepted:
+		default:
+			result.SkippedSections = append(result.SkippedSections, section)
+		}
+	}
+	if len(reportData) == 0 {
+		if len(result.SkippedSections) > 0 {
+			return skip("sections are not done yet")
+		}
+		return skip("sections already accepted")
+	}
+
+	// ghi với tư cách manager đang thao tác, trên đúng version đã đọc để check status
+	report := &model.Report{
+		StudentID:       existing.StudentID,
+		TopicID:         existing.TopicID,
+		TermID:          existing.TermID,
+		Language:        existing.Language,
+		Status:          existing.Status,
+		ReportData:      reportData,
+		ExpectedVersion: &existing.Version,
+		Writer:          editingWriter(ctx, constants.EditingClientWeb),
+	}
+
+	transitions, err := u.reportRepo.CreateOrUpdateClassroomView4Web(ctx, report)
+	if err != nil {
+		result.Result = string(constants.BulkResultFailed)
+		result.Reason = err.Error()
+		return result
+	}
+
+	// save report history
+	history := &model.ReportHistory{
+		ID:          primitive.NewObjectID(),
+		ReportID:    report.ID,
+		EditorID:    helper.GetUserID(ctx),
+		ClassroomID: req.ClassroomID,
+		Type:        string(constants.ReportHistoryTypeWebClassroomView),
+		EditorRole:  string(constants.ReportHistoryRoleManager),
+		Report:      report,
+		Transitions: transitions,
+		Timestamp:   time.Now(),
+	}
+
+	if err := saveHistory(ctx, u.historyRepo, u.statusChangeRepo, u.classroomGw, u.broker, history); err != nil {
+		result.Result = string(constants.BulkResultFailed)
+		result.Reason = err.Error()
+		return result
+	}
+
+	for _, t := range transitions {
+		result.AcceptedSections = append(result.AcceptedSections, t.Section)
+	}
+	result.Result = string(constants.BulkResultAccepted)
+	return result
+}
+
+// ===================================================== AcceptClassroomReports4Web =====================================================//
+
 // ===================================================== GetClassroomReports4Web =====================================================//
 func (u *reportWebUsecase) GetClassroomReports4Web(ctx context.Context, req request.GetClassroomReportRequest4Web) (*response.GetClassroomReportResponse4Web, error) {
 
@@ -259,6 +566,15 @@ func (u *reportWebUsecase) GetClassroomReports4Web(ctx context.Context, req requ
 		return res, nil
 	}
 
+	customSections, err := u.schemas.CustomSections(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := u.loadProgress(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build student reports
 	for _, std := range assigned.AssignTemplates {
 		// get student, teacher info
@@ -269,6 +585,7 @@ func (u *reportWebUsecase) GetClassroomReports4Web(ctx context.Context, req requ
 		}
 
 		report := u.getStudentReport(ctx, req, student, teacher)
+		mapper.MapCustomSections(&report, customSections)
 		reports := response.ClassroomReportResponse4Web{
 			Student: response.StudentReportClassroom{
 				StudentID:     std.StudentID,
@@ -291,7 +608,7 @@ func (u *reportWebUsecase) GetClassroomReports4Web(ctx context.Context, req requ
 		reportList = append(reportList, r.Report)
 	}
 
-	summary := aggregateReportsSummary(reportList)
+	summary := aggregateReportsSummary(reportList, customSections, progress)
 	res.MainPercentage = summary.MainPercentage
 
 	return res, nil
@@ -406,9 +723,10 @@ func (u *reportWebUsecase) getPreviousTermReports(ctx context.Context, currentRe
 
 // ===================================================== GetClassroomReports4Web =====================================================//
 
-func aggregateReportsSummary(reports []response.ReportResponse) response.ReportSummary {
+func aggregateReportsSummary(reports []response.ReportResponse, customSections []*model.ReportSectionDefinition, progress *workflow.Progress) response.ReportSummary {
+	sections := schema.ProgressSections(customSections)
+	sums := make(map[string]float32, len(sections))
 	var total float32
-	var beforeSum, nowSum, conclusionSum float32
 
 	for _, r := range reports {
 		rd := r.ReportData
@@ -416,18 +734,9 @@ func aggregateReportsSummary(reports []response.ReportResponse) response.ReportS
 			continue
 		}
 
-		beforeData := helper.ToBsonM(rd["before"])
-		nowData := helper.ToBsonM(rd["now"])
-		conclusionData := helper.ToBsonM(rd["conclusion"])
-
-		if status, ok := beforeData["status"].(string); ok {
-			beforeSum += constants.MapStatusValue(status)
-		}
-		if status, ok := nowData["status"].(string); ok {
-			nowSum += constants.MapStatusValue(status)
-		}
-		if status, ok := conclusionData["status"].(string); ok {
-			conclusionSum += constants.MapStatusValue(status)
+		values, _ := schema.SectionProgress(rd, customSections, progress)
+		for _, section := range sections {
+			sums[section] += values[section]
 		}
 
 		total++
@@ -437,21 +746,61 @@ func aggregateReportsSummary(reports []response.ReportResponse) response.ReportS
 		return response.ReportSummary{}
 	}
 
-	beforeAvg := beforeSum / total
-	nowAvg := nowSum / total
-	conclusionAvg := conclusionSum / total
+	averages := make(map[string]float32, len(sections))
+	var sum float32
+	for _, section := range sections {
+		averages[section] = sums[section] / total
+		sum += averages[section]
+	}
 
-	mainPercentage := beforeAvg + nowAvg + conclusionAvg
+	mainPercentage := schema.ScaleProgress(sum, len(sections))
 
 	return response.ReportSummary{
 		MainPercentage: mainPercentage,
 		Status:         mainPercentage,
-		Before:         beforeAvg,
-		Now:            nowAvg,
-		Conclusion:     conclusionAvg,
+		Before:         averages["before"],
+		Now:            averages["now"],
+		Conclusion:     averages["conclusion"],
+		Sections:       customAverages(averages, customSections),
 	}
 }
 
+// loadProgress: cách tính điểm status theo bộ status của organization (mặc định nếu chưa cấu hình)
+func (u *reportWebUsecase) loadProgress(ctx context.Context, organizationID string) (*workflow.Progress, error) {
+	statusModel, err := loadStatusModel(ctx, u.statusModelRepo, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return workflow.NewProgress(statusModel), nil
+}
+
+// loadStatusModel: bộ status của organization, mặc định nếu organization chưa cấu hình
+func loadStatusModel(ctx context.Context, repo repository.ReportStatusModelRepository, organizationID string) (model.ReportStatusModel, error) {
+	statusModel, err := repo.GetByOrganization(ctx, organizationID)
+	if err != nil {
+		return model.ReportStatusModel{}, err
+	}
+	if statusModel == nil {
+		return workflow.DefaultStatusModel(), nil
+	}
+	return *statusModel, nil
+}
+
+// customAverages lấy giá trị của các section tuỳ biến có track_progress
+func customAverages(values map[string]float32, customSections []*model.ReportSectionDefinition) map[string]float32 {
+	var res map[string]float32
+	for _, def := range customSections {
+		if !def.TrackProgress {
+			continue
+		}
+		if res == nil {
+			res = make(map[string]float32)
+		}
+		res[def.Key] = values[def.Key]
+	}
+	return res
+}
+
 // ===================================================== GetReportOverViewAllClassroom4Web =====================================================//
 type topicAgg struct {
 	Status response.AllClassroomTopicStatus
@@ -463,6 +812,16 @@ func (u *reportWebUsecase) GetReportOverViewAllClassroom4Web(ctx context.Context
 	var res response.GetReportOverviewAllClassroomResponse4Web
 	res.Classes = make([]response.ClassOverview, 0)
 
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	customSections, err := u.schemas.CustomSections(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := u.loadProgress(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	allClassroomAssignmentTemplate, _ := u.classroomGw.GetAllClassroomAssignTemplate(ctx, req.TermID)
 
 	for _, class := range allClassroomAssignmentTemplate {
@@ -480,7 +839,7 @@ func (u *reportWebUsecase) GetReportOverViewAllClassroom4Web(ctx context.Context
 			}
 
 			// Gọi hàm phụ để xử lý gom dữ liệu
-			classTopics, err := u.aggregateTopicsByClassroom(ctx, reports)
+			classTopics, err := u.aggregateTopicsByClassroom(ctx, reports, customSections, progress)
 			if err != nil {
 				continue
 			}
@@ -489,14 +848,7 @@ func (u *reportWebUsecase) GetReportOverViewAllClassroom4Web(ctx context.Context
 			for topicID, agg := range classTopics {
 				if existing, ok := topicsByClass[topicID]; ok {
 					// Gộp dữ liệu trung bình giữa các nhóm
-					newCount := existing.Count + agg.Count
-					existing.Status.Before = (existing.Status.Before*float32(existing.Count) + agg.Status.Before*float32(agg.Count)) / float32(newCount)
-					existing.Status.Now = (existing.Status.Now*float32(existing.Count) + agg.Status.Now*float32(agg.Count)) / float32(newCount)
-					existing.Status.Conclusion = (existing.Status.Conclusion*float32(existing.Count) + agg.Status.Conclusion*float32(agg.Count)) / float32(newCount)
-					existing.Status.MainStatus = (existing.Status.MainStatus*float32(existing.Count) + agg.Status.MainStatus*float32(agg.Count)) / float32(newCount)
-					existing.Status.MainPercentage = (existing.Status.MainPercentage*float32(existing.Count) + agg.Status.MainPercentage*float32(agg.Count)) / float32(newCount)
-					existing.Count = newCount
-					topicsByClass[topicID] = existing
+					topicsByClass[topicID] = mergeTopicAgg(existing, agg)
 				} else {
 					topicsByClass[topicID] = agg
 				}
@@ -523,7 +875,6 @@ func (u *reportWebUsecase) GetReportOverViewAllClassroom4Web(ctx context.Context
 	}
 
 	// get list all topics
-	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
 	allTopics, err := u.mediaGw.GetAllTopicsByOrganization(ctx, currentUser.OrganizationAdmin.ID)
 	if err != nil {
 		return nil, fmt.Errorf("cannot get all topics: %v", err)
@@ -533,63 +884,74 @@ func (u *reportWebUsecase) GetReportOverViewAllClassroom4Web(ctx context.Context
 	return &res, nil
 }
 
-func (u *reportWebUsecase) aggregateTopicsByClassroom(ctx context.Context, reports []*model.Report) (map[string]topicAgg, error) {
+func (u *reportWebUsecase) aggregateTopicsByClassroom(ctx context.Context, reports []*model.Report, customSections []*model.ReportSectionDefinition, progress *workflow.Progress) (map[string]topicAgg, error) {
 
 	topicsByClass := make(map[string]topicAgg)
 
 	for _, r := range reports {
-		reportStruct, err := mapper.MapReportToStruct(r)
-		if err != nil || reportStruct == nil {
+		if r == nil {
 			continue
 		}
 
-		before := constants.MapStatusValue(reportStruct.ReportData.Before.Status)
-		now := constants.MapStatusValue(reportStruct.ReportData.Now.Status)
-		conclusion := constants.MapStatusValue(reportStruct.ReportData.Conclusion.Status)
-		mainStatus := constants.MapStatusValue(reportStruct.Status)
-		mainPercentage := before + now + conclusion + mainStatus
+		values, sectionsProgress := schema.SectionProgress(r.ReportData, customSections, progress)
+		mainStatus := progress.Value(r.Status)
+		mainPercentage := sectionsProgress + mainStatus
+
+		agg := topicAgg{
+			Status: response.AllClassroomTopicStatus{
+				TopicID:        r.TopicID,
+				Before:         values["before"],
+				Now:            values["now"],
+				Conclusion:     values["conclusion"],
+				Sections:       customAverages(values, customSections),
+				MainPercentage: mainPercentage,
+				MainStatus:     mainStatus,
+			},
+			Count: 1,
+		}
 
 		if existing, ok := topicsByClass[r.TopicID]; ok {
-			newCount := existing.Count + 1
-
-			existing.Status.Before = (existing.Status.Before*float32(existing.Count) + before) / float32(newCount)
-			existing.Status.Now = (existing.Status.Now*float32(existing.Count) + now) / float32(newCount)
-			existing.Status.Conclusion = (existing.Status.Conclusion*float32(existing.Count) + conclusion) / float32(newCount)
-			existing.Status.MainStatus = (existing.Status.MainStatus*float32(existing.Count) + mainStatus) / float32(newCount)
-			existing.Status.MainPercentage = (existing.Status.MainPercentage*float32(existing.Count) + mainPercentage) / float32(newCount)
-
-			existing.Count = newCount
-			topicsByClass[r.TopicID] = existing
-
+			topicsByClass[r.TopicID] = mergeTopicAgg(existing, agg)
 		} else {
 			topic, _ := u.mediaGw.GetTopicByID(ctx, r.TopicID)
-			topicTitle := ""
-			topicMainImageUrl := ""
-
 			if topic != nil {
-				topicTitle = topic.Title
-				topicMainImageUrl = topic.MainImageUrl
+				agg.Status.TopicTitle = topic.Title
+				agg.Status.TopicMainImageUrl = topic.MainImageUrl
 			}
 
-			topicsByClass[r.TopicID] = topicAgg{
-				Status: response.AllClassroomTopicStatus{
-					TopicID:           r.TopicID,
-					TopicTitle:        topicTitle,
-					TopicMainImageUrl: topicMainImageUrl,
-					Before:            before,
-					Now:               now,
-					Conclusion:        conclusion,
-					MainPercentage:    mainPercentage,
-					MainStatus:        mainStatus,
-				},
-				Count: 1,
-			}
+			topicsByClass[r.TopicID] = agg
 		}
 	}
 
 	return topicsByClass, nil
 }
 
+// mergeTopicAgg gộp trung bình (theo số report) của 2 nhóm cùng topic
+func mergeTopicAgg(existing, agg topicAgg) topicAgg {
+	newCount := existing.Count + agg.Count
+	avg := func(a, b float32) float32 {
+		return (a*float32(existing.Count) + b*float32(agg.Count)) / float32(newCount)
+	}
+
+	existing.Status.Before = avg(existing.Status.Before, agg.Status.Before)
+	existing.Status.Now = avg(existing.Status.Now, agg.Status.Now)
+	existing.Status.Conclusion = avg(existing.Status.Conclusion, agg.Status.Conclusion)
+	existing.Status.MainStatus = avg(existing.Status.MainStatus, agg.Status.MainStatus)
+	existing.Status.MainPercentage = avg(existing.Status.MainPercentage, agg.Status.MainPercentage)
+	if len(existing.Status.Sections) > 0 || len(agg.Status.Sections) > 0 {
+		sections := make(map[string]float32, len(existing.Status.Sections))
+		for key := range existing.Status.Sections {
+			sections[key] = avg(existing.Status.Sections[key], agg.Status.Sections[key])
+		}
+		for key := range agg.Status.Sections {
+			sections[key] = avg(existing.Status.Sections[key], agg.Status.Sections[key])
+		}
+		existing.Status.Sections = sections
+	}
+	existing.Count = newCount
+	return existing
+}
+
 // Tính và gán average_topics_percentage cho từng lớp,
 // đồng thời tính overall_classes_percentage cho toàn bộ.
 func (u *reportWebUsecase) fillClassroomAverages(res *response.GetReportOverviewAllClassroomResponse4Web) {
@@ -655,6 +1017,16 @@ func (u *reportWebUsecase) GetReportOverViewByClassroom4Web(ctx context.Context,
 		Avatar: "",
 	}
 
+	currentUser, _ := ctx.Value(constants.CurrentUserKey).(*gw_response.CurrentUser)
+	customSections, err := u.schemas.CustomSections(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := u.loadProgress(ctx, currentUser.OrganizationAdmin.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	res.ClassInfo = response.ClassInfo{
 		ClassName:    classroomAssignmentTemplate.ClassroomName,
 		ClassIconUrl: helper.SafeString(classIconUrl),
@@ -693,7 +1065,7 @@ func (u *reportWebUsecase) GetReportOverViewByClassroom4Web(ctx context.Context,
 		}
 
 		// Gom theo topic
-		classTopics, err := u.aggregateTopicsByClassroom(ctx, reports)
+		classTopics, err := u.aggregateTopicsByClassroom(ctx, reports, customSections, progress)
 		if err != nil {
 			continue
 		}
@@ -701,14 +1073,7 @@ func (u *reportWebUsecase) GetReportOverViewByClassroom4Web(ctx context.Context,
 		// Gộp tổng topic của lớp
 		for topicID, agg := range classTopics {
 			if existing, ok := topicsAgg[topicID]; ok {
-				newCount := existing.Count + agg.Count
-				existing.Status.Before = (existing.Status.Before*float32(existing.Count) + agg.Status.Before*float32(agg.Count)) / float32(newCount)
-				existing.Status.Now = (existing.Status.Now*float32(existing.Count) + agg.Status.Now*float32(agg.Count)) / float32(newCount)
-				existing.Status.Conclusion = (existing.Status.Conclusion*float32(existing.Count) + agg.Status.Conclusion*float32(agg.Count)) / float32(newCount)
-				existing.Status.MainStatus = (existing.Status.MainStatus*float32(existing.Count) + agg.Status.MainStatus*float32(agg.Count)) / float32(newCount)
-				existing.Status.MainPercentage = (existing.Status.MainPercentage*float32(existing.Count) + agg.Status.MainPercentage*float32(agg.Count)) / float32(newCount)
-				existing.Count = newCount
-				topicsAgg[topicID] = existing
+				topicsAgg[topicID] = mergeTopicAgg(existing, agg)
 			} else {
 				topicsAgg[topicID] = agg
 			}
@@ -941,3 +1306,38 @@ func (u *reportWebUsecase) ApplyTopicPlanTemplateIsClassroom2Report(ctx context.
 
 	return nil
 }
+
+func (u *reportWebUsecase) UpdateEditingLock4Web(ctx context.Context, req request.EditingLockRequest4Web, action constants.EditingLockAction) (*model.EditingLock, error) {
+	report, err := u.reportRepo.GetByStudentTopicTermAndLanguage(ctx, req.StudentID, req.TopicID, req.TermID, req.UniqueLangKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateEditingLock(ctx, u.reportRepo, u.broker, report, constants.EditingClientWeb, action)
+}
+
+// SubscribeClassroomEvents4Web đăng ký nhận event của các report thuộc học sinh trong classroom (theo term/topic/language)
+func (u *reportWebUsecase) SubscribeClassroomEvents4Web(ctx context.Context, req request.ClassroomReportEventRequest4Web) (*event.Subscription, error) {
+	if u.broker == nil {
+		return nil, errors.New("report events are not enabled")
+	}
+
+	assigned, err := u.classroomGw.GetClassroomAssignTemplate(ctx, req.TermID, req.ClassroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	studentIDs := make(map[string]struct{})
+	if assigned != nil {
+		for _, std := range assigned.AssignTemplates {
+			studentIDs[std.StudentID] = struct{}{}
+		}
+	}
+
+	return u.broker.Subscribe(event.Scope{
+		TermID:     req.TermID,
+		TopicID:    req.TopicID,
+		Language:   req.UniqueLangKey,
+		StudentIDs: studentIDs,
+	}), nil
+}
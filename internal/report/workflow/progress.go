@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidStatusModel = errors.New("invalid status model")
+
+// SectionScore: điểm tối đa của một section trong overview (3 section + status chung = 100%)
+const SectionScore = 25
+
+// workflowStatuses: thứ tự status theo workflow
+var workflowStatuses = []constants.SectionStatus{
+	constants.SectionStatusEmpty,
+	constants.SectionStatusTeacher,
+	constants.SectionStatusManager,
+	constants.SectionStatusDone,
+	constants.SectionStatusAccepted,
+}
+
+// DefaultStatusModel: bộ status mặc định khi organization chưa cấu hình
+func DefaultStatusModel() model.ReportStatusModel {
+	return model.ReportStatusModel{
+		Statuses: []model.StatusDefinition{
+			{Status: string(constants.SectionStatusEmpty), Label: "Empty", Order: 0, Weight: 0},
+			{Status: string(constants.SectionStatusTeacher), Label: "Teacher", Order: 1, Weight: 10},
+			{Status: string(constants.SectionStatusManager), Label: "Manager", Order: 2, Weight: 15},
+			{Status: string(constants.SectionStatusDone), Label: "Done", Order: 3, Weight: 20},
+			{Status: string(constants.SectionStatusAccepted), Label: "Accepted", Order: 4, Weight: 25},
+		},
+	}
+}
+
+// CheckStatusModel: mỗi status của workflow phải có đúng một định nghĩa, weight không âm và có ít nhất một weight > 0.
+// Tên organization đặt không được trùng nhau và không được trùng status khác của workflow.
+func CheckStatusModel(m model.ReportStatusModel) error {
+	seen := make(map[constants.SectionStatus]bool, len(m.Statuses))
+	names := make(map[string]bool, len(m.Statuses))
+	var max float32
+
+	for _, def := range m.Statuses {
+		status, ok := ParseStatus(def.Status)
+		if !ok || string(status) != def.Status {
+			return fmt.Errorf("%w: unknown status %q", ErrInvalidStatusModel, def.Status)
+		}
+		if seen[status] {
+			return fmt.Errorf("%w: duplicate status %q", ErrInvalidStatusModel, def.Status)
+		}
+		seen[status] = true
+
+		if name := normalizeName(def.Name); name != "" {
+			if other, ok := ParseStatus(name); ok && other != status {
+				return fmt.Errorf("%w: name %q of %q is already status %q", ErrInvalidStatusModel, def.Name, def.Status, other)
+			}
+			if names[name] {
+				return fmt.Errorf("%w: duplicate name %q", ErrInvalidStatusModel, def.Name)
+			}
+			names[name] = true
+		}
+
+		if def.Weight < 0 {
+			return fmt.Errorf("%w: weight of %q must not be negative", ErrInvalidStatusModel, def.Status)
+		}
+		if def.Weight > max {
+			max = def.Weight
+		}
+	}
+
+	for _, status := range workflowStatuses {
+		if !seen[status] {
+			return fmt.Errorf("%w: missing status %q", ErrInvalidStatusModel, status)
+		}
+	}
+	if max == 0 {
+		return fmt.Errorf("%w: at least one weight must be greater than 0", ErrInvalidStatusModel)
+	}
+	return nil
+}
+
+// SortStatuses sắp xếp status theo Order để hiển thị
+func SortStatuses(m *model.ReportStatusModel) {
+	sort.SliceStable(m.Statuses, func(i, j int) bool {
+		return m.Statuses[i].Order < m.Statuses[j].Order
+	})
+}
+
+// StatusNames: tên organization đặt cho status → status của workflow
+type StatusNames map[string]constants.SectionStatus
+
+func NewStatusNames(m model.ReportStatusModel) StatusNames {
+	names := make(StatusNames, len(m.Statuses))
+	for _, def := range m.Statuses {
+		status, ok := ParseStatus(def.Status)
+		name := normalizeName(def.Name)
+		if !ok || name == "" {
+			continue
+		}
+		names[name] = status
+	}
+	return names
+}
+
+// Resolve nhận tên của organization hoặc status của workflow, trả về status của workflow
+func (n StatusNames) Resolve(raw string) (constants.SectionStatus, bool) {
+	if status, ok := n[normalizeName(raw)]; ok {
+		return status, true
+	}
+	return ParseStatus(raw)
+}
+
+// Normalize đổi status theo tên của organization trong report_data về status của workflow trước khi validate / lưu,
+// giá trị không nhận ra giữ nguyên để bước kiểm tra workflow trả lỗi
+func (n StatusNames) Normalize(reportData map[string]interface{}) {
+	if len(n) == 0 {
+		return
+	}
+	for _, data := range reportData {
+		section, ok := data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		raw, ok := section["status"].(string)
+		if !ok {
+			continue
+		}
+		if status, ok := n[normalizeName(raw)]; ok {
+			section["status"] = string(status)
+		}
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Progress quy status text về điểm theo bộ status của organization
+type Progress struct {
+	weights map[constants.SectionStatus]float32
+	names   StatusNames
+	max     float32
+}
+
+func NewProgress(m model.ReportStatusModel) *Progress {
+	p := &Progress{weights: make(map[constants.SectionStatus]float32, len(m.Statuses)), names: NewStatusNames(m)}
+	for _, def := range m.Statuses {
+		status, ok := ParseStatus(def.Status)
+		if !ok {
+			continue
+		}
+		p.weights[status] = def.Weight
+		if def.Weight > p.max {
+			p.max = def.Weight
+		}
+	}
+	return p
+}
+
+// Value trả về điểm của status trên thang 0..SectionScore, status lạ = 0
+func (p *Progress) Value(status string) float32 {
+	if p.max == 0 {
+		return 0
+	}
+	parsed, ok := p.names.Resolve(status)
+	if !ok {
+		return 0
+	}
+	return p.weights[parsed] / p.max * SectionScore
+}
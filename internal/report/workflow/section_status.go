@@ -0,0 +1,156 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"report-service/helper"
+	"report-service/internal/report/model"
+	"report-service/pkg/constants"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid section status transition")
+	ErrUnknownStatus     = errors.New("unknown section status")
+)
+
+// TransitionError mô tả một lần chuyển trạng thái section bị từ chối
+type TransitionError struct {
+	Section string
+	From    constants.SectionStatus
+	To      string
+	Role    constants.ReportHistoryRole
+	Err     error
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("section %q: %s cannot change status from %q to %q: %v", e.Section, e.Role, e.From, e.To, e.Err)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return e.Err
+}
+
+// allowedTransitions: empty → teacher → manager → done → accepted
+// teacher (app) viết rồi nộp cho manager, chỉ manager (web) được duyệt (done), trả lại hoặc accept
+var allowedTransitions = map[constants.ReportHistoryRole]map[constants.SectionStatus][]constants.SectionStatus{
+	constants.ReportHistoryRoleTeacher: {
+		constants.SectionStatusEmpty:   {constants.SectionStatusTeacher},
+		constants.SectionStatusTeacher: {constants.SectionStatusManager},
+	},
+	constants.ReportHistoryRoleManager: {
+		constants.SectionStatusManager:  {constants.SectionStatusTeacher, constants.SectionStatusDone},
+		constants.SectionStatusDone:     {constants.SectionStatusTeacher, constants.SectionStatusAccepted},
+		constants.SectionStatusAccepted: {constants.SectionStatusDone},
+	},
+}
+
+// ParseStatus chuẩn hoá status text, "" được coi là empty
+func ParseStatus(status string) (constants.SectionStatus, bool) {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", string(constants.SectionStatusEmpty):
+		return constants.SectionStatusEmpty, true
+	case string(constants.SectionStatusTeacher):
+		return constants.SectionStatusTeacher, true
+	case string(constants.SectionStatusManager):
+		return constants.SectionStatusManager, true
+	case string(constants.SectionStatusDone):
+		return constants.SectionStatusDone, true
+	case string(constants.SectionStatusAccepted), "approved":
+		return constants.SectionStatusAccepted, true
+	default:
+		return "", false
+	}
+}
+
+// CanTransition kiểm tra role có được chuyển section từ from sang to không
+func CanTransition(role constants.ReportHistoryRole, from, to constants.SectionStatus) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range allowedTransitions[role][from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTransition validate status mới của section so với status hiện tại.
+// Trả về transition (nil nếu status không đổi) và status đã chuẩn hoá để lưu.
+func CheckTransition(role constants.ReportHistoryRole, section, current, next string) (*model.SectionTransition, constants.SectionStatus, error) {
+	// dữ liệu cũ có status lạ thì coi như empty
+	from, ok := ParseStatus(current)
+	if !ok {
+		from = constants.SectionStatusEmpty
+	}
+
+	to, ok := ParseStatus(next)
+	if !ok {
+		return nil, "", &TransitionError{Section: section, From: from, To: next, Role: role, Err: ErrUnknownStatus}
+	}
+
+	if !CanTransition(role, from, to) {
+		return nil, "", &TransitionError{Section: section, From: from, To: string(to), Role: role, Err: ErrInvalidTransition}
+	}
+
+	if from == to {
+		return nil, to, nil
+	}
+
+	return &model.SectionTransition{
+		Section: section,
+		From:    string(from),
+		To:      string(to),
+		Role:    string(role),
+	}, to, nil
+}
+
+// StatusChanges liệt kê các section có status thay đổi giữa 2 report_data (không validate),
+// dùng cho các thao tác ghi đè như restore
+func StatusChanges(role constants.ReportHistoryRole, current, target bson.M) []model.SectionTransition {
+	var transitions []model.SectionTransition
+
+	for section, data := range target {
+		targetStatus, _ := helper.ToBsonM(data)["status"].(string)
+		currentStatus, _ := helper.ToBsonM(current[section])["status"].(string)
+
+		from, ok := ParseStatus(currentStatus)
+		if !ok {
+			from = constants.SectionStatusEmpty
+		}
+		to, ok := ParseStatus(targetStatus)
+		if !ok {
+			to = constants.SectionStatusEmpty
+		}
+		if from == to {
+			continue
+		}
+
+		transitions = append(transitions, model.SectionTransition{
+			Section: section,
+			From:    string(from),
+			To:      string(to),
+			Role:    string(role),
+		})
+	}
+
+	for section, data := range current {
+		if _, ok := target[section]; ok {
+			continue
+		}
+		currentStatus, _ := helper.ToBsonM(data)["status"].(string)
+		if from, ok := ParseStatus(currentStatus); ok && from != constants.SectionStatusEmpty {
+			transitions = append(transitions, model.SectionTransition{
+				Section: section,
+				From:    string(from),
+				To:      string(constants.SectionStatusEmpty),
+				Role:    string(role),
+			})
+		}
+	}
+
+	return transitions
+}
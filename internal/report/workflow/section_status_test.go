@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"errors"
+	"report-service/pkg/constants"
+	"testing"
+)
+
+var allStatuses = []constants.SectionStatus{
+	constants.SectionStatusEmpty,
+	constants.SectionStatusTeacher,
+	constants.SectionStatusManager,
+	constants.SectionStatusDone,
+	constants.SectionStatusAccepted,
+}
+
+func TestCheckTransitionMatrix(t *testing.T) {
+	type move struct{ from, to constants.SectionStatus }
+	allowed := map[constants.ReportHistoryRole]map[move]bool{
+		constants.ReportHistoryRoleTeacher: {
+			{constants.SectionStatusEmpty, constants.SectionStatusTeacher}:   true,
+			{constants.SectionStatusTeacher, constants.SectionStatusManager}: true,
+		},
+		constants.ReportHistoryRoleManager: {
+			{constants.SectionStatusManager, constants.SectionStatusTeacher}: true,
+			{constants.SectionStatusManager, constants.SectionStatusDone}:    true,
+			{constants.SectionStatusDone, constants.SectionStatusTeacher}:    true,
+			{constants.SectionStatusDone, constants.SectionStatusAccepted}:   true,
+			{constants.SectionStatusAccepted, constants.SectionStatusDone}:   true,
+		},
+	}
+
+	for role, moves := range allowed {
+		for _, from := range allStatuses {
+			for _, to := range allStatuses {
+				name := string(role) + ":" + string(from) + "->" + string(to)
+				transition, status, err := CheckTransition(role, "goal", string(from), string(to))
+
+				switch {
+				case from == to:
+					if err != nil || transition != nil || status != to {
+						t.Errorf("%s: got transition=%v status=%q err=%v, want no-op", name, transition, status, err)
+					}
+				case moves[move{from, to}]:
+					if err != nil {
+						t.Errorf("%s: unexpected error %v", name, err)
+						continue
+					}
+					if transition == nil || transition.From != string(from) || transition.To != string(to) ||
+						transition.Role != string(role) || transition.Section != "goal" || status != to {
+						t.Errorf("%s: got transition=%+v status=%q", name, transition, status)
+					}
+				default:
+					if !errors.Is(err, ErrInvalidTransition) {
+						t.Errorf("%s: got err=%v, want ErrInvalidTransition", name, err)
+					}
+					var te *TransitionError
+					if !errors.As(err, &te) || te.Section != "goal" || te.From != from || te.Role != role {
+						t.Errorf("%s: got %#v, want TransitionError for the move", name, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCheckTransitionNormalizesStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		role    constants.ReportHistoryRole
+		current string
+		next    string
+		want    constants.SectionStatus
+		changed bool
+		err     error
+	}{
+		{"blank current is empty", constants.ReportHistoryRoleTeacher, "", "teacher", constants.SectionStatusTeacher, true, nil},
+		{"legacy current is empty", constants.ReportHistoryRoleTeacher, "draft", "teacher", constants.SectionStatusTeacher, true, nil},
+		{"case and spaces", constants.ReportHistoryRoleTeacher, " Teacher ", "MANAGER", constants.SectionStatusManager, true, nil},
+		{"approved alias", constants.ReportHistoryRoleManager, "done", "Approved", constants.SectionStatusAccepted, true, nil},
+		{"alias of same status", constants.ReportHistoryRoleManager, "accepted", "approved", constants.SectionStatusAccepted, false, nil},
+		{"unknown next", constants.ReportHistoryRoleManager, "done", "finished", "", false, ErrUnknownStatus},
+		{"teacher cannot accept", constants.ReportHistoryRoleTeacher, "done", "accepted", "", false, ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transition, status, err := CheckTransition(tt.role, "note", tt.current, tt.next)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if status != tt.want {
+				t.Errorf("status = %q, want %q", status, tt.want)
+			}
+			if (transition != nil) != tt.changed {
+				t.Errorf("transition = %+v, want changed=%v", transition, tt.changed)
+			}
+		})
+	}
+}
@@ -33,6 +33,35 @@ type MongoDBConfig struct {
 	Name     string `yaml:"name"`
 }
 
+type HistoryConfig struct {
+	Mode               string `yaml:"mode"` // "full" or "delta"
+	CheckpointInterval int    `yaml:"checkpoint_interval"`
+}
+
+type IdempotencyConfig struct {
+	TTLHours int `yaml:"ttl_hours"`
+}
+
+type ReminderConfig struct {
+	Enabled           bool   `yaml:"enabled"`
+	IntervalMinutes   int    `yaml:"interval_minutes"`
+	LookaheadHours    int    `yaml:"lookahead_hours"`
+	OverdueWindowDays int    `yaml:"overdue_window_days"`
+	CooldownHours     int    `yaml:"cooldown_hours"`
+	WebhookURL        string `yaml:"webhook_url"` // rỗng = chỉ ghi log
+}
+
+type ExportConfig struct {
+	FontPath     string `yaml:"font_path"`      // TTF có dấu tiếng Việt, rỗng = font chuẩn (bỏ dấu)
+	BoldFontPath string `yaml:"bold_font_path"` // rỗng = dùng font_path
+
+	// job xuất ZIP theo lớp
+	StorageDir        string `yaml:"storage_dir"` // thư mục lưu file ZIP, rỗng = thư mục tạm
+	JobWorkers        int    `yaml:"job_workers"`
+	JobQueueSize      int    `yaml:"job_queue_size"`
+	JobTimeoutMinutes int    `yaml:"job_timeout_minutes"`
+}
+
 type ConsulConfig struct {
 	Host string `yaml:"host"`
 	Port int    `yaml:"port"`
@@ -78,12 +107,16 @@ type Registry struct {
 }
 
 type AppConfigStruct struct {
-	Server   ServerConfig     `yaml:"server"`
-	Database DatabaseConfig   `yaml:"database"`
-	Consul   ConsulConfig     `yaml:"consul"`
-	Zap      ZapConfig        `mapstructure:"zap"`
-	Registry Registry         `mapstructure:"registry" validate:"required"`
-	App      AppConfiguration `mapstructure:"app"`
+	Server      ServerConfig      `yaml:"server"`
+	Database    DatabaseConfig    `yaml:"database"`
+	Consul      ConsulConfig      `yaml:"consul"`
+	History     HistoryConfig     `yaml:"history"`
+	Idempotency IdempotencyConfig `yaml:"idempotency"`
+	Reminder    ReminderConfig    `yaml:"reminder"`
+	Export      ExportConfig      `yaml:"export"`
+	Zap         ZapConfig         `mapstructure:"zap"`
+	Registry    Registry          `mapstructure:"registry" validate:"required"`
+	App         AppConfiguration  `mapstructure:"app"`
 }
 
 var AppConfig *AppConfigStruct
@@ -1,6 +1,8 @@
 package constants
 
-import "strings"
+import (
+	"time"
+)
 
 const (
 	GrpcPort                   = "GRPC_PORT"
@@ -142,31 +144,119 @@ const (
 type ReportHistoryType string
 
 const (
-	ReportHistoryTypeAppStudentView   ReportHistoryRole = "app_student_view"
-	ReportHistoryTypeWebStudentView   ReportHistoryRole = "web_student_view"
-	ReportHistoryTypeWebClassroomView ReportHistoryRole = "web_classroom_view"
+	ReportHistoryTypeAppStudentView   ReportHistoryType = "app_student_view"
+	ReportHistoryTypeWebStudentView   ReportHistoryType = "web_student_view"
+	ReportHistoryTypeWebClassroomView ReportHistoryType = "web_classroom_view"
+	ReportHistoryTypeWebSendBack      ReportHistoryType = "web_send_back"
+	ReportHistoryTypeWebRestore       ReportHistoryType = "web_restore"
 )
 
 const (
-	StatusEmpty    = 0
-	StatusTeacher  = 10
-	StatusManager  = 15
-	StatusDone     = 20
-	StatusAccepted = 25
+	HistoryStorageFull  = "full"
+	HistoryStorageDelta = "delta"
 )
 
-// MapStatusValue convert text status → numeric value
-func MapStatusValue(status string) float32 {
-	switch strings.ToLower(status) {
-	case "teacher":
-		return StatusTeacher
-	case "manager":
-		return StatusManager
-	case "done":
-		return StatusDone
-	case "accepted":
-		return StatusAccepted
-	default:
-		return StatusEmpty
-	}
-}
+type SectionStatus string
+
+const (
+	SectionStatusEmpty    SectionStatus = "empty"
+	SectionStatusTeacher  SectionStatus = "teacher"
+	SectionStatusManager  SectionStatus = "manager"
+	SectionStatusDone     SectionStatus = "done"
+	SectionStatusAccepted SectionStatus = "accepted"
+)
+
+type BulkResult string
+
+const (
+	BulkResultAccepted BulkResult = "accepted"
+	BulkResultSkipped  BulkResult = "skipped"
+	BulkResultFailed   BulkResult = "failed"
+)
+
+type SyncPushResult string
+
+const (
+	SyncPushApplied  SyncPushResult = "applied"
+	SyncPushConflict SyncPushResult = "conflict"
+	SyncPushLocked   SyncPushResult = "locked"
+	SyncPushFailed   SyncPushResult = "failed"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+type EditingClient string
+
+const (
+	EditingClientApp EditingClient = "app"
+	EditingClientWeb EditingClient = "web"
+)
+
+type EditingLockAction string
+
+const (
+	EditingLockAcquire EditingLockAction = "acquire"
+	EditingLockRenew   EditingLockAction = "renew"
+	EditingLockRelease EditingLockAction = "release"
+)
+
+type ReportEventType string
+
+const (
+	ReportEventSaved         ReportEventType = "report_saved"
+	ReportEventStatusChanged ReportEventType = "status_changed"
+	ReportEventLockChanged   ReportEventType = "lock_changed"
+)
+
+// ReportSectionType: kiểu section tuỳ biến do organization định nghĩa
+type ReportSectionType string
+
+const (
+	ReportSectionTypeText      ReportSectionType = "text"
+	ReportSectionTypeRating    ReportSectionType = "rating"
+	ReportSectionTypeChecklist ReportSectionType = "checklist"
+)
+
+// ReviewQueueReason: lý do section cần manager xử lý
+type ReviewQueueReason string
+
+const (
+	ReviewQueueAwaitingAcceptance  ReviewQueueReason = "awaiting_acceptance"
+	ReviewQueueUpdatedAfterComment ReviewQueueReason = "updated_after_comment"
+)
+
+type ReviewQueueSort string
+
+const (
+	ReviewQueueSortOldest ReviewQueueSort = "oldest"
+	ReviewQueueSortNewest ReviewQueueSort = "newest"
+)
+
+// ReportExportJobStatus: trạng thái job xuất file hàng loạt
+type ReportExportJobStatus string
+
+const (
+	ReportExportJobQueued    ReportExportJobStatus = "queued"
+	ReportExportJobRunning   ReportExportJobStatus = "running"
+	ReportExportJobCompleted ReportExportJobStatus = "completed"
+	ReportExportJobFailed    ReportExportJobStatus = "failed"
+)
+
+type ReportExportFormat string
+
+const (
+	ReportExportFormatPDF  ReportExportFormat = "pdf"
+	ReportExportFormatHTML ReportExportFormat = "html"
+	ReportExportFormatDOCX ReportExportFormat = "docx"
+	ReportExportFormatCSV  ReportExportFormat = "csv"
+	ReportExportFormatXLSX ReportExportFormat = "xlsx"
+)
+
+// EditingLockTTL: thời gian giữ lock, client cần renew trước khi hết hạn
+const EditingLockTTL = 2 * time.Minute
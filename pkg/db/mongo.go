@@ -16,6 +16,15 @@ var ReportCollection *mongo.Collection
 var ReportHistoryCollection *mongo.Collection
 var ReportPlanTemplateCollection *mongo.Collection
 var ReportTranslateCollection *mongo.Collection
+var IdempotencyKeyCollection *mongo.Collection
+var ReportSchemaCollection *mongo.Collection
+var ReportSectionCollection *mongo.Collection
+var ReportStatusModelCollection *mongo.Collection
+var ReportDeadlineCollection *mongo.Collection
+var ReportStatusChangeCollection *mongo.Collection
+var ReportExportJobCollection *mongo.Collection
+var CounterCollection *mongo.Collection
+var ReportReminderCollection *mongo.Collection
 
 func ConnectMongoDB() {
 	d := config.AppConfig.Database.Mongo
@@ -52,5 +61,14 @@ func ConnectMongoDB() {
 	ReportHistoryCollection = MongoClient.Database(d.Name).Collection("report_histories")
 	ReportPlanTemplateCollection = MongoClient.Database(d.Name).Collection("report_plan_template")
 	ReportTranslateCollection = MongoClient.Database(d.Name).Collection("report_translates")
+	IdempotencyKeyCollection = MongoClient.Database(d.Name).Collection("idempotency_keys")
+	ReportSchemaCollection = MongoClient.Database(d.Name).Collection("report_schemas")
+	ReportSectionCollection = MongoClient.Database(d.Name).Collection("report_sections")
+	ReportStatusModelCollection = MongoClient.Database(d.Name).Collection("report_status_models")
+	ReportDeadlineCollection = MongoClient.Database(d.Name).Collection("report_deadlines")
+	ReportStatusChangeCollection = MongoClient.Database(d.Name).Collection("report_status_changes")
+	ReportExportJobCollection = MongoClient.Database(d.Name).Collection("report_export_jobs")
+	CounterCollection = MongoClient.Database(d.Name).Collection("counters")
+	ReportReminderCollection = MongoClient.Database(d.Name).Collection("report_reminder_logs")
 	log.Println("Connected to MongoDB and loaded 'reports' collection")
 }
@@ -0,0 +1,255 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// đơn vị: twip (1/20 point) cho trang, EMU (1/914400 inch) cho ảnh
+const (
+	pageWidth    = 11906 // A4
+	pageHeight   = 16838
+	pageMargin   = 1134 // 2 cm
+	emuPerTwip   = 635
+	emuPerPixel  = 9525 // 96 dpi
+	contentWidth = (pageWidth - 2*pageMargin) * emuPerTwip
+)
+
+type Style int
+
+const (
+	Normal Style = iota
+	Title
+	Heading1
+	Heading2
+	Subtle
+	Strong
+)
+
+func (s Style) id() string {
+	switch s {
+	case Title:
+		return "Title"
+	case Heading1:
+		return "Heading1"
+	case Heading2:
+		return "Heading2"
+	case Subtle:
+		return "Subtle"
+	case Strong:
+		return "Strong"
+	}
+	return ""
+}
+
+type docImage struct {
+	name string
+	data []byte
+}
+
+// Document dựng file .docx (OOXML) đơn giản: đoạn văn theo style, ảnh inline.
+// Layout cố định ở mức tối thiểu để người dùng tự chỉnh lại trong Word.
+type Document struct {
+	body   strings.Builder
+	images []*docImage
+}
+
+func New() *Document {
+	return &Document{}
+}
+
+// Paragraph thêm một đoạn, xuống dòng trong text được giữ nguyên
+func (d *Document) Paragraph(text string, style Style) {
+	d.paragraph(style, run(text, false))
+}
+
+// Field thêm đoạn dạng "label: value" với label in đậm
+func (d *Document) Field(label, value string) {
+	d.paragraph(Normal, run(label+": ", true)+run(value, false))
+}
+
+// Image thêm ảnh inline, co lại vừa maxWidth x maxHeight pixel và giữ tỉ lệ.
+// text khác rỗng thì ghi cùng dòng bên phải ảnh.
+func (d *Document) Image(img image.Image, maxWidth, maxHeight int, text string, style Style) error {
+	drawing, err := d.drawing(img, maxWidth, maxHeight)
+	if err != nil {
+		return err
+	}
+	if text != "" {
+		drawing += `<w:r><w:t xml:space="preserve">  </w:t></w:r>` + run(text, false)
+	}
+	d.paragraph(style, drawing)
+	return nil
+}
+
+func (d *Document) paragraph(style Style, runs string) {
+	d.body.WriteString("<w:p>")
+	if id := style.id(); id != "" {
+		fmt.Fprintf(&d.body, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, id)
+	}
+	d.body.WriteString(runs)
+	d.body.WriteString("</w:p>")
+}
+
+func run(text string, bold bool) string {
+	var sb strings.Builder
+	sb.WriteString("<w:r>")
+	if bold {
+		sb.WriteString("<w:rPr><w:b/></w:rPr>")
+	}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if i > 0 {
+			sb.WriteString("<w:br/>")
+		}
+		sb.WriteString(`<w:t xml:space="preserve">`)
+		sb.WriteString(escape(line))
+		sb.WriteString("</w:t>")
+	}
+	sb.WriteString("</w:r>")
+	return sb.String()
+}
+
+// escape: ký tự không hợp lệ trong XML được thay bằng U+FFFD
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (d *Document) drawing(img image.Image, maxWidth, maxHeight int) (string, error) {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", err
+	}
+	n := len(d.images) + 1
+	d.images = append(d.images, &docImage{name: fmt.Sprintf("image%d.png", n), data: buf.Bytes()})
+
+	cx, cy := fit(b.Dx(), b.Dy(), maxWidth, maxHeight)
+	if cx > contentWidth {
+		cy = cy * contentWidth / cx
+		cx = contentWidth
+	}
+	return fmt.Sprintf(`<w:r><w:drawing><wp:inline distT="0" distB="0" distL="0" distR="0">`+
+		`<wp:extent cx="%[1]d" cy="%[2]d"/><wp:docPr id="%[3]d" name="Picture %[3]d"/>`+
+		`<wp:cNvGraphicFramePr><a:graphicFrameLocks noChangeAspect="1"/></wp:cNvGraphicFramePr>`+
+		`<a:graphic><a:graphicData uri="http://schemas.openxmlformats.org/drawingml/2006/picture">`+
+		`<pic:pic><pic:nvPicPr><pic:cNvPr id="%[3]d" name="image%[3]d.png"/><pic:cNvPicPr/></pic:nvPicPr>`+
+		`<pic:blipFill><a:blip r:embed="rIdImage%[3]d"/><a:stretch><a:fillRect/></a:stretch></pic:blipFill>`+
+		`<pic:spPr><a:xfrm><a:off x="0" y="0"/><a:ext cx="%[1]d" cy="%[2]d"/></a:xfrm><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></pic:spPr>`+
+		`</pic:pic></a:graphicData></a:graphic></wp:inline></w:drawing></w:r>`, cx, cy, n), nil
+}
+
+// fit trả về kích thước EMU của ảnh w x h pixel sau khi co vừa maxW x maxH pixel
+func fit(w, h, maxW, maxH int) (int, int) {
+	scale := 1.0
+	if w > maxW {
+		scale = float64(maxW) / float64(w)
+	}
+	if float64(h)*scale > float64(maxH) {
+		scale = float64(maxH) / float64(h)
+	}
+	return int(float64(w) * scale * emuPerPixel), int(float64(h) * scale * emuPerPixel)
+}
+
+// Bytes đóng gói các part thành file .docx
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypesXML)},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"word/document.xml", d.documentXML()},
+		{"word/styles.xml", []byte(stylesXML)},
+		{"word/_rels/document.xml.rels", d.documentRelsXML()},
+	}
+	for _, im := range d.images {
+		parts = append(parts, struct {
+			name string
+			data []byte
+		}{"word/media/" + im.name, im.data})
+	}
+
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(p.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Document) documentXML() []byte {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"` +
+		` xmlns:wp="http://schemas.openxmlformats.org/drawingml/2006/wordprocessingDrawing"` +
+		` xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"` +
+		` xmlns:pic="http://schemas.openxmlformats.org/drawingml/2006/picture"><w:body>`)
+	sb.WriteString(d.body.String())
+	fmt.Fprintf(&sb, `<w:sectPr><w:pgSz w:w="%d" w:h="%d"/><w:pgMar w:top="%[3]d" w:right="%[3]d" w:bottom="%[3]d" w:left="%[3]d" w:header="708" w:footer="708" w:gutter="0"/></w:sectPr>`,
+		pageWidth, pageHeight, pageMargin)
+	sb.WriteString("</w:body></w:document>")
+	return []byte(sb.String())
+}
+
+func (d *Document) documentRelsXML() []byte {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	sb.WriteString(`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, im := range d.images {
+		fmt.Fprintf(&sb, `<Relationship Id="rIdImage%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/%s"/>`, i+1, im.name)
+	}
+	sb.WriteString("</Relationships>")
+	return []byte(sb.String())
+}
+
+const contentTypesXML = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Default Extension="png" ContentType="image/png"/>` +
+	`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+	`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+	`</Types>`
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+	`</Relationships>`
+
+// style dùng tên chuẩn của Word (heading 1, ...) để hiện trong Navigation pane và mục lục
+const stylesXML = xml.Header + `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+	`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Arial" w:hAnsi="Arial" w:eastAsia="Arial" w:cs="Arial"/><w:sz w:val="22"/><w:szCs w:val="22"/><w:lang w:val="vi-VN"/></w:rPr></w:rPrDefault>` +
+	`<w:pPrDefault><w:pPr><w:spacing w:after="120" w:line="276" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+	`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/><w:qFormat/></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:spacing w:before="240" w:after="120"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/><w:szCs w:val="36"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="360" w:after="80"/><w:outlineLvl w:val="0"/></w:pPr><w:rPr><w:b/><w:sz w:val="28"/><w:szCs w:val="28"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/><w:qFormat/>` +
+	`<w:pPr><w:keepNext/><w:spacing w:before="240" w:after="60"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/><w:sz w:val="24"/><w:szCs w:val="24"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:customStyle="1" w:styleId="Subtle"><w:name w:val="Subtle"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+	`<w:rPr><w:i/><w:color w:val="666666"/><w:sz w:val="20"/><w:szCs w:val="20"/></w:rPr></w:style>` +
+	`<w:style w:type="paragraph" w:customStyle="1" w:styleId="Strong"><w:name w:val="Strong"/><w:basedOn w:val="Normal"/><w:qFormat/>` +
+	`<w:rPr><w:b/><w:sz w:val="28"/><w:szCs w:val="28"/></w:rPr></w:style>` +
+	`</w:styles>`
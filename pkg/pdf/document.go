@@ -0,0 +1,332 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strings"
+	"unicode/utf8"
+)
+
+// khổ A4 theo point (1/72 inch)
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Fonts: font TTF đã parse, dùng chung được giữa các Document.
+// nil hoặc thiếu regular thì dùng Helvetica (bỏ dấu tiếng Việt).
+type Fonts struct {
+	regular *trueTypeFont
+	bold    *trueTypeFont
+}
+
+// LoadFonts parse font TTF, bold rỗng thì dùng regular cho cả chữ đậm
+func LoadFonts(regular, bold []byte) (*Fonts, error) {
+	r, err := parseTrueType("ReportFont-Regular", regular)
+	if err != nil {
+		return nil, err
+	}
+	fonts := &Fonts{regular: r, bold: r}
+	if len(bold) > 0 {
+		if fonts.bold, err = parseTrueType("ReportFont-Bold", bold); err != nil {
+			return nil, err
+		}
+	}
+	return fonts, nil
+}
+
+func (f *trueTypeFont) clone() *trueTypeFont {
+	c := *f
+	c.used = make(map[uint16]rune)
+	return &c
+}
+
+type page struct {
+	content bytes.Buffer
+}
+
+type pdfImage struct {
+	name   string
+	width  int
+	height int
+	rgb    []byte
+}
+
+// Document dựng PDF nhiều trang theo kiểu dòng chảy từ trên xuống,
+// toạ độ y tính từ mép trên trang
+type Document struct {
+	fonts  map[Style]font
+	margin float64
+	pages  []*page
+	images []*pdfImage
+	y      float64
+	footer func(page, total int) string
+}
+
+func New(fonts *Fonts, margin float64) *Document {
+	d := &Document{
+		fonts:  map[Style]font{Regular: helvetica, Bold: helveticaBold},
+		margin: margin,
+	}
+	if fonts != nil && fonts.regular != nil {
+		regular := fonts.regular.clone()
+		d.fonts[Regular] = regular
+		d.fonts[Bold] = regular
+		if fonts.bold != nil && fonts.bold != fonts.regular {
+			d.fonts[Bold] = fonts.bold.clone()
+		}
+	}
+	d.AddPage()
+	return d
+}
+
+// SetFooter: nội dung chân trang, được vẽ khi xuất vì cần tổng số trang
+func (d *Document) SetFooter(footer func(page, total int) string) {
+	d.footer = footer
+}
+
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &page{})
+	d.y = d.margin
+}
+
+// Y là vị trí hiện tại của con trỏ
+func (d *Document) Y() float64 {
+	return d.y
+}
+
+func (d *Document) SetY(y float64) {
+	d.y = y
+}
+
+// ContentWidth là độ rộng vùng nội dung giữa 2 lề
+func (d *Document) ContentWidth() float64 {
+	return PageWidth - 2*d.margin
+}
+
+func (d *Document) bottom() float64 {
+	return PageHeight - d.margin
+}
+
+// ensure sang trang mới nếu không còn đủ h point
+func (d *Document) ensure(h float64) {
+	if d.y+h > d.bottom() && d.y > d.margin {
+		d.AddPage()
+	}
+}
+
+func (d *Document) Space(h float64) {
+	d.y += h
+}
+
+func (d *Document) TextWidth(s string, style Style, size float64) float64 {
+	return d.fonts[style].width(s) * size / 1000
+}
+
+// TextAt vẽ một dòng text, (x, y) là góc trên bên trái dòng
+func (d *Document) TextAt(x, y float64, s string, style Style, size float64) {
+	d.text(d.current(), x, y, s, style, size, 0)
+}
+
+func (d *Document) text(p *page, x, y float64, s string, style Style, size float64, gray float64) {
+	if s == "" {
+		return
+	}
+	baseline := PageHeight - y - size*0.8
+	fmt.Fprintf(&p.content, "BT %s g /F%d %s Tf %s %s Td %s Tj ET\n",
+		num(gray), int(style)+1, num(size), num(x), num(baseline), d.fonts[style].encode(s))
+}
+
+// Paragraph viết text tự xuống dòng trong vùng nội dung và tự sang trang
+func (d *Document) Paragraph(s string, style Style, size float64) {
+	lineHeight := size * 1.4
+	for _, line := range d.wrap(s, style, size, d.ContentWidth()) {
+		d.ensure(lineHeight)
+		d.text(d.current(), d.margin, d.y, line, style, size, 0)
+		d.y += lineHeight
+	}
+}
+
+// Line kẻ ngang hết vùng nội dung tại vị trí hiện tại
+func (d *Document) Line() {
+	y := PageHeight - d.y
+	fmt.Fprintf(&d.current().content, "0.8 G 0.5 w %s %s m %s %s l S\n",
+		num(d.margin), num(y), num(PageWidth-d.margin), num(y))
+}
+
+// ImageAt chèn ảnh tại (x, y) với kích thước w x h point
+func (d *Document) ImageAt(img image.Image, x, y, w, h float64) {
+	name := d.addImage(img)
+	fmt.Fprintf(&d.current().content, "q %s 0 0 %s %s %s cm /%s Do Q\n",
+		num(w), num(h), num(x), num(PageHeight-y-h), name)
+}
+
+// Image chèn ảnh tại con trỏ, co lại vừa maxW x maxH và giữ tỉ lệ
+func (d *Document) Image(img image.Image, maxW, maxH float64) {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return
+	}
+	w, h := fit(float64(b.Dx()), float64(b.Dy()), maxW, maxH)
+	d.ensure(h)
+	d.ImageAt(img, d.margin, d.y, w, h)
+	d.y += h
+}
+
+func fit(w, h, maxW, maxH float64) (float64, float64) {
+	scale := 1.0
+	if w > maxW {
+		scale = maxW / w
+	}
+	if h*scale > maxH {
+		scale = maxH / h
+	}
+	return w * scale, h * scale
+}
+
+func (d *Document) addImage(img image.Image) string {
+	b := img.Bounds()
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := img.At(x, y).RGBA()
+			// ảnh trong suốt được phủ lên nền trắng
+			white := 0xffff - a
+			rgb = append(rgb, byte((r+white)>>8), byte((g+white)>>8), byte((bl+white)>>8))
+		}
+	}
+
+	im := &pdfImage{
+		name:   fmt.Sprintf("Im%d", len(d.images)+1),
+		width:  b.Dx(),
+		height: b.Dy(),
+		rgb:    rgb,
+	}
+	d.images = append(d.images, im)
+	return im.name
+}
+
+func (d *Document) current() *page {
+	return d.pages[len(d.pages)-1]
+}
+
+// wrap ngắt dòng theo từ, từ dài hơn cả dòng thì ngắt theo ký tự
+func (d *Document) wrap(s string, style Style, size, maxWidth float64) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		words := strings.Fields(para)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		line := ""
+		for _, word := range words {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if d.TextWidth(candidate, style, size) <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for d.TextWidth(word, style, size) > maxWidth && utf8.RuneCountInString(word) > 1 {
+				cut := d.fit(word, style, size, maxWidth)
+				lines = append(lines, word[:cut])
+				word = word[cut:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fit trả về số byte dài nhất của s vừa maxWidth (ít nhất 1 ký tự)
+func (d *Document) fit(s string, style Style, size, maxWidth float64) int {
+	cut := 0
+	for i, r := range s {
+		next := i + utf8.RuneLen(r)
+		if cut > 0 && d.TextWidth(s[:next], style, size) > maxWidth {
+			break
+		}
+		cut = next
+	}
+	return cut
+}
+
+// Bytes xuất file PDF
+func (d *Document) Bytes() ([]byte, error) {
+	if d.footer != nil {
+		for i, p := range d.pages {
+			text := d.footer(i+1, len(d.pages))
+			x := (PageWidth - d.TextWidth(text, Regular, 9)) / 2
+			d.text(p, x, PageHeight-d.margin/2-4.5, text, Regular, 9, 0.4)
+		}
+	}
+
+	w := newWriter()
+	catalog, pages := w.reserve(), w.reserve()
+
+	var resources strings.Builder
+	resources.WriteString("<< /Font <<")
+	// regular và bold cùng một font thì chỉ nhúng một lần
+	fontRefs := make([]int, 0, 2)
+	for style := Regular; style <= Bold; style++ {
+		n := 0
+		if style == Bold && d.fonts[Bold] == d.fonts[Regular] {
+			n = fontRefs[Regular]
+		} else {
+			n = w.reserve()
+		}
+		fontRefs = append(fontRefs, n)
+		fmt.Fprintf(&resources, " /F%d %s", int(style)+1, ref(n))
+	}
+	resources.WriteString(" >>")
+	imageRefs := make([]int, 0, len(d.images))
+	if len(d.images) > 0 {
+		resources.WriteString(" /XObject <<")
+		for _, im := range d.images {
+			n := w.reserve()
+			imageRefs = append(imageRefs, n)
+			fmt.Fprintf(&resources, " /%s %s", im.name, ref(n))
+		}
+		resources.WriteString(" >>")
+	}
+	resources.WriteString(" >>")
+
+	kids := make([]string, 0, len(d.pages))
+	for _, p := range d.pages {
+		pageRef, contentRef := w.reserve(), w.reserve()
+		kids = append(kids, ref(pageRef))
+		w.object(pageRef, fmt.Sprintf("<< /Type /Page /Parent %s /MediaBox [0 0 %s %s] /Resources %s /Contents %s >>",
+			ref(pages), num(PageWidth), num(PageHeight), resources.String(), ref(contentRef)))
+		if err := w.stream(contentRef, "", p.content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, im := range d.images {
+		extra := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8", im.width, im.height)
+		if err := w.stream(imageRefs[i], extra, im.rgb); err != nil {
+			return nil, err
+		}
+	}
+	// font ghi sau content vì TTF chỉ biết glyph nào được dùng sau khi đã vẽ xong
+	for i, n := range fontRefs {
+		if i > 0 && n == fontRefs[i-1] {
+			continue
+		}
+		if err := d.fonts[Style(i)].write(w, n, fmt.Sprintf("F%d", i+1)); err != nil {
+			return nil, err
+		}
+	}
+
+	w.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %s >>", ref(pages)))
+	return w.finish(catalog)
+}
@@ -0,0 +1,105 @@
+package pdf
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Style int
+
+const (
+	Regular Style = iota
+	Bold
+)
+
+// font: độ rộng tính theo đơn vị 1/1000 em
+type font interface {
+	width(s string) float64
+	encode(s string) string
+	write(w *writer, n int, name string) error
+}
+
+// standardFont dùng font Type1 có sẵn trong mọi trình đọc PDF (không cần nhúng),
+// chỉ hỗ trợ ASCII nên chữ có dấu được bỏ dấu
+type standardFont struct {
+	baseFont string
+	widths   [95]int
+}
+
+func (f *standardFont) width(s string) float64 {
+	var total float64
+	for _, c := range asciiFold(s) {
+		total += float64(f.widths[c-32])
+	}
+	return total
+}
+
+func (f *standardFont) encode(s string) string {
+	return literal(asciiFold(s))
+}
+
+func (f *standardFont) write(w *writer, n int, name string) error {
+	w.object(n, "<< /Type /Font /Subtype /Type1 /BaseFont /"+f.baseFont+" /Encoding /WinAnsiEncoding >>")
+	return nil
+}
+
+// asciiFold bỏ dấu (NFD rồi bỏ dấu kết hợp), ký tự không biểu diễn được thành '?'
+func asciiFold(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ':
+			out = append(out, 'd')
+		case r == 'Đ':
+			out = append(out, 'D')
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r < 127:
+			out = append(out, byte(r))
+		case unicode.IsSpace(r):
+			out = append(out, ' ')
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+// độ rộng ký tự 32..126 theo AFM của Helvetica
+var helvetica = &standardFont{
+	baseFont: "Helvetica",
+	widths: [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	},
+}
+
+var helveticaBold = &standardFont{
+	baseFont: "Helvetica-Bold",
+	widths: [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	},
+}
+
+// pdfName bỏ các ký tự không hợp lệ trong PDF name
+func pdfName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r > 32 && r < 127 && !strings.ContainsRune("()<>[]{}/%#", r) {
+			return r
+		}
+		return -1
+	}, s)
+}
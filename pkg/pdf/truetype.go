@@ -0,0 +1,307 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var ErrInvalidFont = errors.New("pdf: invalid truetype font")
+
+// trueTypeFont nhúng nguyên file TTF (Type0/Identity-H) để hiển thị được tiếng Việt
+type trueTypeFont struct {
+	name       string
+	data       []byte
+	unitsPerEm float64
+	advances   []uint16
+	cmap       map[rune]uint16
+	bbox       [4]int16
+	ascent     int16
+	descent    int16
+
+	used map[uint16]rune
+}
+
+func parseTrueType(name string, data []byte) (*trueTypeFont, error) {
+	tables, err := ttfTables(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range []string{"head", "hhea", "hmtx", "cmap", "maxp"} {
+		if _, ok := tables[tag]; !ok {
+			return nil, fmt.Errorf("%w: missing %s table", ErrInvalidFont, tag)
+		}
+	}
+
+	head, hhea, maxp := tables["head"], tables["hhea"], tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, ErrInvalidFont
+	}
+
+	f := &trueTypeFont{
+		name:       pdfName(name),
+		data:       data,
+		unitsPerEm: float64(binary.BigEndian.Uint16(head[18:])),
+		ascent:     int16(binary.BigEndian.Uint16(hhea[4:])),
+		descent:    int16(binary.BigEndian.Uint16(hhea[6:])),
+		used:       make(map[uint16]rune),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, ErrInvalidFont
+	}
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	if numHMetrics == 0 || len(hmtx) < numHMetrics*4 {
+		return nil, ErrInvalidFont
+	}
+	// glyph sau numHMetrics dùng advance của metric cuối
+	f.advances = make([]uint16, numGlyphs)
+	for i := range f.advances {
+		m := i
+		if m >= numHMetrics {
+			m = numHMetrics - 1
+		}
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[m*4:])
+	}
+
+	if f.cmap, err = parseCmap(tables["cmap"]); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func ttfTables(data []byte) (map[string][]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrInvalidFont
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: // TrueType, 'true'
+	default:
+		return nil, fmt.Errorf("%w: only TrueType outlines are supported", ErrInvalidFont)
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	if len(data) < 12+numTables*16 {
+		return nil, ErrInvalidFont
+	}
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := data[12+i*16:]
+		offset := int(binary.BigEndian.Uint32(rec[8:]))
+		length := int(binary.BigEndian.Uint32(rec[12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, ErrInvalidFont
+		}
+		tables[string(rec[:4])] = data[offset : offset+length]
+	}
+	return tables, nil
+}
+
+// parseCmap đọc subtable unicode: ưu tiên format 12 (full unicode), sau đó format 4 (BMP)
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrInvalidFont
+	}
+	var format4, format12 []byte
+	numTables := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < numTables; i++ {
+		if len(cmap) < 4+i*8+8 {
+			return nil, ErrInvalidFont
+		}
+		rec := cmap[4+i*8:]
+		platform := binary.BigEndian.Uint16(rec)
+		encoding := binary.BigEndian.Uint16(rec[2:])
+		offset := int(binary.BigEndian.Uint32(rec[4:]))
+		if offset+4 > len(cmap) {
+			continue
+		}
+		if platform != 0 && !(platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		switch binary.BigEndian.Uint16(cmap[offset:]) {
+		case 4:
+			format4 = cmap[offset:]
+		case 12:
+			format12 = cmap[offset:]
+		}
+	}
+
+	switch {
+	case format12 != nil:
+		return parseCmap12(format12)
+	case format4 != nil:
+		return parseCmap4(format4)
+	}
+	return nil, fmt.Errorf("%w: no unicode cmap", ErrInvalidFont)
+}
+
+func parseCmap4(t []byte) (map[rune]uint16, error) {
+	if len(t) < 14 {
+		return nil, ErrInvalidFont
+	}
+	segX2 := int(binary.BigEndian.Uint16(t[6:]))
+	endCodes := 14
+	startCodes := endCodes + segX2 + 2
+	deltas := startCodes + segX2
+	rangeOffsets := deltas + segX2
+	if len(t) < rangeOffsets+segX2 {
+		return nil, ErrInvalidFont
+	}
+
+	m := make(map[rune]uint16)
+	for s := 0; s < segX2; s += 2 {
+		end := binary.BigEndian.Uint16(t[endCodes+s:])
+		start := binary.BigEndian.Uint16(t[startCodes+s:])
+		delta := binary.BigEndian.Uint16(t[deltas+s:])
+		rangeOffset := int(binary.BigEndian.Uint16(t[rangeOffsets+s:]))
+		for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+			var gid uint16
+			if rangeOffset == 0 {
+				gid = uint16(c) + delta
+			} else {
+				idx := rangeOffsets + s + rangeOffset + int(c-uint32(start))*2
+				if idx+2 > len(t) {
+					continue
+				}
+				if gid = binary.BigEndian.Uint16(t[idx:]); gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				m[rune(c)] = gid
+			}
+		}
+	}
+	return m, nil
+}
+
+func parseCmap12(t []byte) (map[rune]uint16, error) {
+	if len(t) < 16 {
+		return nil, ErrInvalidFont
+	}
+	numGroups := int(binary.BigEndian.Uint32(t[12:]))
+	if len(t) < 16+numGroups*12 {
+		return nil, ErrInvalidFont
+	}
+
+	m := make(map[rune]uint16)
+	for i := 0; i < numGroups; i++ {
+		g := t[16+i*12:]
+		start := binary.BigEndian.Uint32(g)
+		end := binary.BigEndian.Uint32(g[4:])
+		gid := binary.BigEndian.Uint32(g[8:])
+		for c := start; c <= end && c <= 0x10FFFF; c++ {
+			m[rune(c)] = uint16(gid + c - start)
+		}
+	}
+	return m, nil
+}
+
+func (f *trueTypeFont) glyph(r rune) uint16 {
+	if r == '\t' {
+		r = ' '
+	}
+	return f.cmap[r]
+}
+
+func (f *trueTypeFont) advance(gid uint16) float64 {
+	if int(gid) >= len(f.advances) {
+		return 0
+	}
+	return float64(f.advances[gid]) * 1000 / f.unitsPerEm
+}
+
+// text được chuẩn hoá NFC để chữ có dấu gõ kiểu tổ hợp vẫn dùng glyph dựng sẵn
+func (f *trueTypeFont) width(s string) float64 {
+	var total float64
+	for _, r := range norm.NFC.String(s) {
+		total += f.advance(f.glyph(r))
+	}
+	return total
+}
+
+func (f *trueTypeFont) encode(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('<')
+	for _, r := range norm.NFC.String(s) {
+		gid := f.glyph(r)
+		if _, ok := f.used[gid]; !ok && gid != 0 {
+			f.used[gid] = r
+		}
+		fmt.Fprintf(&sb, "%04X", gid)
+	}
+	sb.WriteByte('>')
+	return sb.String()
+}
+
+func (f *trueTypeFont) write(w *writer, n int, name string) error {
+	cidFont, descriptor, file, toUnicode := w.reserve(), w.reserve(), w.reserve(), w.reserve()
+	baseFont := f.name
+	if baseFont == "" {
+		baseFont = name
+	}
+
+	gids := make([]int, 0, len(f.used))
+	for gid := range f.used {
+		gids = append(gids, int(gid))
+	}
+	sort.Ints(gids)
+
+	var widths strings.Builder
+	for _, gid := range gids {
+		fmt.Fprintf(&widths, "%d [%s] ", gid, num(f.advance(uint16(gid))))
+	}
+
+	scale := func(v int16) string { return num(float64(v) * 1000 / f.unitsPerEm) }
+
+	w.object(n, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%s] /ToUnicode %s >>",
+		baseFont, ref(cidFont), ref(toUnicode)))
+	w.object(cidFont, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %s /CIDToGIDMap /Identity /DW 1000 /W [%s] >>",
+		baseFont, ref(descriptor), widths.String()))
+	w.object(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %s >>",
+		baseFont, scale(f.bbox[0]), scale(f.bbox[1]), scale(f.bbox[2]), scale(f.bbox[3]),
+		scale(f.ascent), scale(f.descent), scale(f.ascent), ref(file)))
+	if err := w.stream(file, fmt.Sprintf("/Length1 %d", len(f.data)), f.data); err != nil {
+		return err
+	}
+	return w.stream(toUnicode, "", f.toUnicode(gids))
+}
+
+// toUnicode: CMap để copy/tìm kiếm text trong PDF ra đúng ký tự
+func (f *trueTypeFont) toUnicode(gids []int) []byte {
+	var sb strings.Builder
+	sb.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n")
+	sb.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	sb.WriteString("/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n")
+	sb.WriteString("1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+
+	// mỗi khối bfchar tối đa 100 dòng
+	for i := 0; i < len(gids); i += 100 {
+		end := i + 100
+		if end > len(gids) {
+			end = len(gids)
+		}
+		fmt.Fprintf(&sb, "%d beginbfchar\n", end-i)
+		for _, gid := range gids[i:end] {
+			fmt.Fprintf(&sb, "<%04X> <", gid)
+			for _, u := range utf16.Encode([]rune{f.used[uint16(gid)]}) {
+				fmt.Fprintf(&sb, "%04X", u)
+			}
+			sb.WriteString(">\n")
+		}
+		sb.WriteString("endbfchar\n")
+	}
+
+	sb.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return []byte(sb.String())
+}
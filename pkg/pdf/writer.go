@@ -0,0 +1,98 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// writer ghi các object PDF và bảng xref
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func newWriter() *writer {
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	return w
+}
+
+// reserve cấp số object trước, để object khác tham chiếu trước khi được ghi
+func (w *writer) reserve() int {
+	w.offsets = append(w.offsets, -1)
+	return len(w.offsets)
+}
+
+func (w *writer) object(n int, body string) {
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+// stream ghi stream nén FlateDecode, extra là các key bổ sung của dictionary
+func (w *writer) stream(n int, extra string, data []byte) error {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	w.offsets[n-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Filter /FlateDecode /Length %d >>\nstream\n", n, extra, compressed.Len())
+	w.buf.Write(compressed.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return nil
+}
+
+func (w *writer) finish(root int) ([]byte, error) {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for i, off := range w.offsets {
+		if off < 0 {
+			return nil, fmt.Errorf("pdf: object %d was reserved but never written", i+1)
+		}
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, xref)
+	return w.buf.Bytes(), nil
+}
+
+func ref(n int) string {
+	return strconv.Itoa(n) + " 0 R"
+}
+
+// num định dạng số thực gọn cho content stream
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-" {
+		return "0"
+	}
+	return s
+}
+
+// literal escape chuỗi byte thành PDF string (...)
+func literal(b []byte) string {
+	var sb strings.Builder
+	sb.WriteByte('(')
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(')')
+	return sb.String()
+}
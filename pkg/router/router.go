@@ -1,19 +1,31 @@
 package router
 
 import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
 	"report-service/internal/gateway"
+	"report-service/internal/report/event"
+	"report-service/internal/report/export"
+	"report-service/internal/report/exportjob"
 	"report-service/internal/report/handler"
+	"report-service/internal/report/reminder"
 	"report-service/internal/report/repository"
 	"report-service/internal/report/route"
+	"report-service/internal/report/schema"
 	"report-service/internal/report/service"
 	"report-service/internal/report/usecase"
+	"report-service/pkg/blobstore"
+	"report-service/pkg/config"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/consul/api"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRouter(consulClient *api.Client, reportCollection, reportHistoryCollection, reportPlanTemplateCollection, reportTranslateCollection *mongo.Collection) *gin.Engine {
+func SetupRouter(consulClient *api.Client, reportCollection, reportHistoryCollection, reportPlanTemplateCollection, reportTranslateCollection, idempotencyKeyCollection, reportSchemaCollection, reportSectionCollection, reportStatusModelCollection, reportDeadlineCollection, reportStatusChangeCollection, reportExportJobCollection, counterCollection, reportReminderCollection *mongo.Collection) *gin.Engine {
 	r := gin.Default()
 
 	// gateway
@@ -24,18 +36,79 @@ func SetupRouter(consulClient *api.Client, reportCollection, reportHistoryCollec
 	fileGateway := gateway.NewFileGateway("go-main-service", consulClient)
 
 	// Setup dependency injection
-	reportRepo := repository.NewReportRepository(reportCollection)
-	historyRepo := repository.NewReportHistoryRepository(reportHistoryCollection)
+	reportRepo := repository.NewReportRepository(reportCollection, counterCollection)
+	if err := reportRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report indexes: %v", err)
+	}
+	historyRepo := repository.NewReportHistoryRepository(reportHistoryCollection, repository.HistoryStorage{
+		Mode:               config.AppConfig.History.Mode,
+		CheckpointInterval: config.AppConfig.History.CheckpointInterval,
+	})
+	if err := historyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report history indexes: %v", err)
+	}
 	reportPlanTemplateRepo := repository.NewReportPlanTemplateRepository(reportPlanTemplateCollection)
+	idempotencyRepo := repository.NewIdempotencyRepository(idempotencyKeyCollection, time.Duration(config.AppConfig.Idempotency.TTLHours)*time.Hour)
+	if err := idempotencyRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create idempotency key indexes: %v", err)
+	}
+
+	reportSchemaRepo := repository.NewReportSchemaRepository(reportSchemaCollection)
+	reportSectionRepo := repository.NewReportSectionRepository(reportSectionCollection)
+	if err := reportSectionRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report section indexes: %v", err)
+	}
+	schemaRegistry := schema.NewRegistry(reportSchemaRepo, reportSectionRepo)
+	reportStatusModelRepo := repository.NewReportStatusModelRepository(reportStatusModelCollection)
+	reportDeadlineRepo := repository.NewReportDeadlineRepository(reportDeadlineCollection)
+	if err := reportDeadlineRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report deadline indexes: %v", err)
+	}
+	reportStatusChangeRepo := repository.NewReportStatusChangeRepository(reportStatusChangeCollection)
+	if err := reportStatusChangeRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report status change indexes: %v", err)
+	}
+	reportExportJobRepo := repository.NewReportExportJobRepository(reportExportJobCollection)
+	if err := reportExportJobRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Failed to create report export job indexes: %v", err)
+	}
+	// queue nằm trong memory, job dở dang trước khi restart không chạy tiếp được
+	if n, err := reportExportJobRepo.FailUnfinished(context.Background(), "interrupted by server restart"); err != nil {
+		log.Printf("Failed to mark unfinished report export jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d unfinished report export jobs as failed", n)
+	}
+
+	// event broker cho SSE, in-process (1 instance)
+	reportBroker := event.NewMemoryBroker()
+
+	// nhắc teacher các section quá hạn / sắp đến hạn
+	if cfg := config.AppConfig.Reminder; cfg.Enabled {
+		var notifier reminder.Notifier = reminder.NewLogNotifier()
+		if cfg.WebhookURL != "" {
+			notifier = reminder.NewWebhookNotifier(cfg.WebhookURL, nil)
+		}
+		reportReminderRepo := repository.NewReportReminderRepository(reportReminderCollection)
+		if err := reportReminderRepo.EnsureIndexes(context.Background()); err != nil {
+			log.Printf("Failed to create report reminder indexes: %v", err)
+		}
+		scheduler := reminder.NewScheduler(reportRepo, reportDeadlineRepo, reportReminderRepo, schemaRegistry, notifier, reminder.SystemClock(), reminder.Config{
+			Interval:      time.Duration(cfg.IntervalMinutes) * time.Minute,
+			Lookahead:     time.Duration(cfg.LookaheadHours) * time.Hour,
+			OverdueWindow: time.Duration(cfg.OverdueWindowDays) * 24 * time.Hour,
+			Cooldown:      time.Duration(cfg.CooldownHours) * time.Hour,
+		})
+		go scheduler.Start(context.Background())
+	}
 
 	// report
-	reportAppUseCase := usecase.NewReportAppUseCase(reportRepo, historyRepo, userGateway, classroomGateway, termGateway, mediaGateway)
-	reportWebUseCase := usecase.NewReportWebUsecase(reportRepo, historyRepo, reportPlanTemplateRepo, userGateway, classroomGateway, termGateway, mediaGateway, fileGateway)
+	reportAppUseCase := usecase.NewReportAppUseCase(reportRepo, historyRepo, userGateway, classroomGateway, termGateway, mediaGateway, reportBroker, schemaRegistry, reportDeadlineRepo, reportStatusChangeRepo, reportStatusModelRepo)
+	reportWebUseCase := usecase.NewReportWebUsecase(reportRepo, historyRepo, reportPlanTemplateRepo, userGateway, classroomGateway, termGateway, mediaGateway, fileGateway, reportBroker, schemaRegistry, reportStatusModelRepo, reportStatusChangeRepo)
 	reportService := service.NewReportService(reportAppUseCase, reportWebUseCase)
 	reportHandler := handler.NewReportHandler(reportService)
 
 	// report history
-	reportHistoryService := service.NewReportHistoryService(historyRepo)
+	reportHistoryService := service.NewReportHistoryService(historyRepo, reportRepo)
 	reportHistoryHandler := handler.NewReportHistoryHandler(reportHistoryService)
 
 	// report plan template
@@ -47,7 +120,45 @@ func SetupRouter(consulClient *api.Client, reportCollection, reportHistoryCollec
 	reportTranslateService := service.NewReportTranslateService(reportTranslateRepo, mediaGateway)
 	reportTranslateHandler := handler.NewReportTranslateHandler(reportTranslateService)
 
+	// report schema
+	reportSchemaService := service.NewReportSchemaService(reportSchemaRepo, schemaRegistry)
+	reportSchemaHandler := handler.NewReportSchemaHandler(reportSchemaService)
+
+	// report custom section
+	reportSectionService := service.NewReportSectionService(reportSectionRepo)
+	reportSectionHandler := handler.NewReportSectionHandler(reportSectionService)
+
+	// report status model
+	reportStatusModelService := service.NewReportStatusModelService(reportStatusModelRepo)
+	reportStatusModelHandler := handler.NewReportStatusModelHandler(reportStatusModelService)
+
+	// report deadline
+	reportDeadlineService := service.NewReportDeadlineService(reportDeadlineRepo)
+	reportDeadlineHandler := handler.NewReportDeadlineHandler(reportDeadlineService)
+
+	// report export: font lỗi thì vẫn xuất được bằng font chuẩn
+	exportFonts, err := export.LoadFonts(config.AppConfig.Export.FontPath, config.AppConfig.Export.BoldFontPath)
+	if err != nil {
+		log.Printf("Failed to load export fonts, using standard fonts: %v", err)
+	}
+	storageDir := config.AppConfig.Export.StorageDir
+	if storageDir == "" {
+		storageDir = filepath.Join(os.TempDir(), "report-exports")
+	}
+	exportStore, err := blobstore.NewLocalStore(storageDir)
+	if err != nil {
+		log.Fatalf("Failed to init export storage: %v", err)
+	}
+	exportQueue := exportjob.NewQueue(reportExportJobRepo, exportStore, exportjob.Config{
+		Workers:   config.AppConfig.Export.JobWorkers,
+		QueueSize: config.AppConfig.Export.JobQueueSize,
+		Timeout:   time.Duration(config.AppConfig.Export.JobTimeoutMinutes) * time.Minute,
+	})
+	go exportQueue.Start(context.Background())
+	reportExportService := service.NewReportExportService(reportRepo, reportTranslateRepo, reportExportJobRepo, userGateway, classroomGateway, termGateway, mediaGateway, exportQueue, exportStore, exportFonts)
+	reportExportHandler := handler.NewReportExportHandler(reportExportService)
+
 	// Register routes
-	route.RegisterReportRoutes(r, reportHandler, reportHistoryHandler, reportPlanTemplateHandler, reportTranslateHandler, userGateway)
+	route.RegisterReportRoutes(r, reportHandler, reportHistoryHandler, reportPlanTemplateHandler, reportTranslateHandler, reportSchemaHandler, reportSectionHandler, reportStatusModelHandler, reportDeadlineHandler, reportExportHandler, userGateway, idempotencyRepo)
 	return r
 }
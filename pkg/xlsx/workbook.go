@@ -0,0 +1,263 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// style index trong cellXfs của styles.xml
+const (
+	styleDefault = 0
+	styleBold    = 1
+)
+
+// Workbook dựng file .xlsx (SpreadsheetML) tối thiểu: nhiều sheet, text/số, header in đậm.
+// Text ghi dạng inline string nên không cần sharedStrings.
+type Workbook struct {
+	sheets []*Sheet
+}
+
+type Sheet struct {
+	name       string
+	rows       []string
+	widths     map[int]float64
+	freezeRows int
+	freezeCols int
+}
+
+func New() *Workbook {
+	return &Workbook{}
+}
+
+// AddSheet thêm sheet, tên được làm sạch theo giới hạn của Excel và không trùng
+func (w *Workbook) AddSheet(name string) *Sheet {
+	name = sheetName(name)
+	base := name
+	for i := 2; w.hasSheet(name); i++ {
+		suffix := fmt.Sprintf(" (%d)", i)
+		name = truncate(base, 31-len(suffix)) + suffix
+	}
+	s := &Sheet{name: name, widths: make(map[int]float64)}
+	w.sheets = append(w.sheets, s)
+	return s
+}
+
+func (w *Workbook) hasSheet(name string) bool {
+	for _, s := range w.sheets {
+		if strings.EqualFold(s.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+	return truncate(name, 31)
+}
+
+// truncate cắt theo số ký tự (không cắt giữa rune)
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// Header thêm một dòng in đậm
+func (s *Sheet) Header(values ...interface{}) {
+	s.addRow(styleBold, values)
+}
+
+// Row thêm một dòng: string là text, số là số, nil là ô trống
+func (s *Sheet) Row(values ...interface{}) {
+	s.addRow(styleDefault, values)
+}
+
+// Freeze cố định rows dòng trên cùng và cols cột bên trái khi cuộn
+func (s *Sheet) Freeze(rows, cols int) {
+	s.freezeRows, s.freezeCols = rows, cols
+}
+
+// SetColumnWidth đặt độ rộng cột (col tính từ 0) theo số ký tự
+func (s *Sheet) SetColumnWidth(col int, width float64) {
+	s.widths[col] = width
+}
+
+func (s *Sheet) addRow(style int, values []interface{}) {
+	n := len(s.rows) + 1
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<row r="%d">`, n)
+	for i, v := range values {
+		ref := cellRef(i, n-1)
+		switch v := v.(type) {
+		case nil:
+			continue
+		case string:
+			fmt.Fprintf(&sb, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(v))
+		case int:
+			fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%d</v></c>`, ref, style, v)
+		case float32:
+			fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(float64(v), 'f', -1, 32))
+		case float64:
+			fmt.Fprintf(&sb, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			fmt.Fprintf(&sb, `<c r="%s" t="inlineStr" s="%d"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(fmt.Sprint(v)))
+		}
+	}
+	sb.WriteString("</row>")
+	s.rows = append(s.rows, sb.String())
+}
+
+// cellRef: (0, 0) -> A1
+func cellRef(col, row int) string {
+	return columnName(col) + strconv.Itoa(row+1)
+}
+
+func columnName(col int) string {
+	name := ""
+	for col++; col > 0; col = (col - 1) / 26 {
+		name = string(rune('A'+(col-1)%26)) + name
+	}
+	return name
+}
+
+// escape: ký tự không hợp lệ trong XML được thay bằng U+FFFD
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func (s *Sheet) xml() []byte {
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if s.freezeRows > 0 || s.freezeCols > 0 {
+		pane := "bottomRight"
+		switch {
+		case s.freezeCols == 0:
+			pane = "bottomLeft"
+		case s.freezeRows == 0:
+			pane = "topRight"
+		}
+		sb.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane`)
+		if s.freezeCols > 0 {
+			fmt.Fprintf(&sb, ` xSplit="%d"`, s.freezeCols)
+		}
+		if s.freezeRows > 0 {
+			fmt.Fprintf(&sb, ` ySplit="%d"`, s.freezeRows)
+		}
+		fmt.Fprintf(&sb, ` topLeftCell="%s" activePane="%s" state="frozen"/></sheetView></sheetViews>`,
+			cellRef(s.freezeCols, s.freezeRows), pane)
+	}
+	if len(s.widths) > 0 {
+		cols := make([]int, 0, len(s.widths))
+		for col := range s.widths {
+			cols = append(cols, col)
+		}
+		sort.Ints(cols)
+
+		sb.WriteString("<cols>")
+		for _, col := range cols {
+			fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, col+1, col+1, strconv.FormatFloat(s.widths[col], 'f', -1, 64))
+		}
+		sb.WriteString("</cols>")
+	}
+	sb.WriteString("<sheetData>")
+	for _, row := range s.rows {
+		sb.WriteString(row)
+	}
+	sb.WriteString("</sheetData></worksheet>")
+	return []byte(sb.String())
+}
+
+// Bytes đóng gói các part thành file .xlsx
+func (w *Workbook) Bytes() ([]byte, error) {
+	if len(w.sheets) == 0 {
+		w.AddSheet("Sheet1")
+	}
+
+	var contentTypes, workbook, rels strings.Builder
+	contentTypes.WriteString(xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"` +
+		` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, s := range w.sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rIdSheet%d"/>`, escape(s.name), n, n)
+		fmt.Fprintf(&rels, `<Relationship Id="rIdSheet%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+	}
+	contentTypes.WriteString("</Types>")
+	workbook.WriteString("</sheets></workbook>")
+	rels.WriteString("</Relationships>")
+
+	parts := []struct {
+		name string
+		data []byte
+	}{
+		{"[Content_Types].xml", []byte(contentTypes.String())},
+		{"_rels/.rels", []byte(rootRelsXML)},
+		{"xl/workbook.xml", []byte(workbook.String())},
+		{"xl/_rels/workbook.xml.rels", []byte(rels.String())},
+		{"xl/styles.xml", []byte(stylesXML)},
+	}
+	for i, s := range w.sheets {
+		parts = append(parts, struct {
+			name string
+			data []byte
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), s.xml()})
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, p := range parts {
+		fw, err := zw.Create(p.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fw.Write(p.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// fills bắt buộc có none và gray125 ở 2 vị trí đầu
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`